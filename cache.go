@@ -0,0 +1,102 @@
+package dotenv
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrStaleCache is returned by LoadCache when a source file has changed,
+// appeared or disappeared since the cache was written.
+var ErrStaleCache = errors.New("cache is out of date")
+
+// cache is the content of a file written by CacheTo.
+type cache struct {
+	Files []cachedFile
+	Vars  map[string]string
+}
+
+// cachedFile records the state of a source file when the cache was built.
+type cachedFile struct {
+	Name    string
+	Exists  bool
+	Size    int64
+	ModTime time.Time
+}
+
+// CacheTo parses files, or FilenameVariables when none are given, like
+// Loader.Parse and writes the result to the cache file path, together with
+// the size and modification time of each source file. It is meant for hot
+// paths such as shell hooks, which can then call LoadCache instead of
+// parsing the files on every run.
+func CacheTo(path string, files ...string) error {
+	loader := Loader{Filenames: files}
+	return loader.CacheTo(path)
+}
+
+// CacheTo parses the loader's files like Parse and writes the result to the
+// cache file path like the package-level CacheTo. Files read through
+// @include lines are recorded too, so changing one also makes the cache
+// stale.
+func (l *Loader) CacheTo(path string) error {
+	var included []string
+	loader := *l
+	loader.included = &included
+
+	vars, err := loader.Parse()
+	if err != nil {
+		return err
+	}
+
+	c := cache{Vars: vars}
+	for _, name := range append(loader.filenames(), included...) {
+		c.Files = append(c.Files, statFile(name))
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		return fmt.Errorf("encoding cache: %w", err)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// LoadCache returns the variables stored in the cache file path by CacheTo.
+// It returns ErrStaleCache when any source file's size or modification time
+// differs from when the cache was written, in which case the caller should
+// parse the files again and rebuild the cache.
+func LoadCache(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c cache
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decoding cache %s: %w", path, err)
+	}
+
+	for _, file := range c.Files {
+		current := statFile(file.Name)
+		if current.Exists != file.Exists || current.Size != file.Size || !current.ModTime.Equal(file.ModTime) {
+			return nil, fmt.Errorf("%w: %s changed", ErrStaleCache, file.Name)
+		}
+	}
+
+	if c.Vars == nil {
+		c.Vars = make(map[string]string)
+	}
+	return c.Vars, nil
+}
+
+// statFile returns the current state of the file name.
+func statFile(name string) cachedFile {
+	info, err := os.Stat(name)
+	if err != nil {
+		return cachedFile{Name: name}
+	}
+	return cachedFile{Name: name, Exists: true, Size: info.Size(), ModTime: info.ModTime()}
+}
@@ -0,0 +1,42 @@
+package dotenv
+
+import (
+	"os"
+	"os/exec"
+)
+
+// ApplyToCmd appends the key-value pairs of the tagged fields of dest to
+// cmd.Env, so the child process sees them without modifying the current
+// process environment. When cmd.Env is nil it starts from os.Environ().
+func ApplyToCmd(cmd *exec.Cmd, dest interface{}) error {
+	pairs, err := marshalPairs(dest)
+	if err != nil {
+		return err
+	}
+
+	env := cmdEnv(cmd)
+	for _, p := range pairs {
+		env = append(env, p.key+"="+p.value)
+	}
+	cmd.Env = env
+
+	return nil
+}
+
+// ApplyMapToCmd appends vars to cmd.Env like ApplyToCmd, for variables that
+// were parsed into a map rather than a struct.
+func ApplyMapToCmd(cmd *exec.Cmd, vars map[string]string) {
+	env := cmdEnv(cmd)
+	for key, value := range vars {
+		env = append(env, key+"="+value)
+	}
+	cmd.Env = env
+}
+
+// cmdEnv returns the environment a command would run with.
+func cmdEnv(cmd *exec.Cmd) []string {
+	if cmd.Env == nil {
+		return os.Environ()
+	}
+	return cmd.Env
+}
@@ -0,0 +1,89 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkConditions enforces the 'requiredIf' and 'requiredUnless' tags once
+// every field has been resolved. resolved holds the value set for each key.
+func checkConditions(fields []field, resolved map[string]string, src source) error {
+	value := func(key string) string {
+		if v, ok := resolved[key]; ok {
+			return v
+		}
+		v, _ := src.lookup(key)
+		return v
+	}
+
+	for _, f := range fields {
+		if f.key == "" || resolved[f.key] != "" {
+			continue
+		}
+
+		if f.requiredIf != "" {
+			key, expected, _ := strings.Cut(f.requiredIf, "=")
+			if value(key) == expected {
+				return fmt.Errorf("error %s is required when %s", f.name, f.requiredIf)
+			}
+		}
+
+		if f.requiredUnless != "" {
+			key, expected, _ := strings.Cut(f.requiredUnless, "=")
+			if value(key) != expected {
+				return fmt.Errorf("error %s is required unless %s", f.name, f.requiredUnless)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkGroups enforces the 'requiredGroup' tag: for each group, at least
+// one of its fields must have been set to a non-empty value. Groups are
+// checked in the order their first field is declared.
+func checkGroups(fields []field, resolved map[string]string) error {
+	var groups []string
+	keys := make(map[string][]string)
+	satisfied := make(map[string]bool)
+	for _, f := range fields {
+		if f.requiredGroup == "" {
+			continue
+		}
+
+		if _, ok := keys[f.requiredGroup]; !ok {
+			groups = append(groups, f.requiredGroup)
+		}
+		keys[f.requiredGroup] = append(keys[f.requiredGroup], f.key)
+		if resolved[f.key] != "" {
+			satisfied[f.requiredGroup] = true
+		}
+	}
+
+	for _, group := range groups {
+		if !satisfied[group] {
+			return fmt.Errorf("error at least one of %s must be set (group %s)", strings.Join(keys[group], ", "), group)
+		}
+	}
+
+	return nil
+}
+
+// conditionalDefault returns the default of the field's 'defaultIf' rule
+// when the rule's condition holds, or "" otherwise. The condition is checked
+// against the value resolved for an earlier field with that key, or against
+// the variable itself.
+func (f field) conditionalDefault(resolved map[string]string, src source) string {
+	key, rest, _ := strings.Cut(f.defaultIf, "=")
+	expected, value, _ := strings.Cut(rest, ":")
+
+	current, ok := resolved[key]
+	if !ok {
+		current, _ = src.lookup(key)
+	}
+
+	if current != expected {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+package dotenv
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Decoder reads variables from a configuration file in some format. It is
+// the extension point for formats other than .env, such as TOML or INI.
+type Decoder interface {
+	Decode(r io.Reader) (map[string]string, error)
+}
+
+// EnvDecoder is the built-in Decoder for .env files. It parses with the
+// rules of the zero Loader. Files read by a Loader are parsed with that
+// loader's own options instead.
+type EnvDecoder struct{}
+
+// Decode parses r as a .env file.
+func (EnvDecoder) Decode(r io.Reader) (map[string]string, error) {
+	loader := Loader{}
+	return loader.ParseReaders(r)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{".env": EnvDecoder{}}
+)
+
+// RegisterDecoder makes Load, Collect and the Loader methods read files
+// whose extension is ext, such as ".toml", with d. Extensions are matched
+// case-insensitively, and registering an extension again replaces its
+// decoder. Files with an unregistered extension, such as .env.local, are
+// parsed as .env files.
+func RegisterDecoder(ext string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[strings.ToLower(ext)] = d
+}
+
+// decoderFor returns the decoder registered for the extension of filename,
+// or nil when the file should be parsed as a .env file by the loader.
+func decoderFor(filename string) Decoder {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	d := decoders[strings.ToLower(filepath.Ext(filename))]
+	if _, ok := d.(EnvDecoder); ok {
+		return nil
+	}
+	return d
+}
+
+// parseFile parses content read from filename into vars, dispatching to
+// the decoder registered for its extension. Entries from a custom decoder
+// carry no line numbers and are sorted by key.
+func (l *Loader) parseFile(filename, content string, vars map[string]string) ([]Entry, error) {
+	d := decoderFor(filename)
+	if d == nil {
+		return l.parse(filename, content, vars)
+	}
+
+	decoded, err := d.Decode(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", filename, err)
+	}
+
+	keys := make([]string, 0, len(decoded))
+	for key := range decoded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		vars[key] = decoded[key]
+		entries = append(entries, Entry{Key: key, Value: decoded[key], File: filename})
+	}
+	return entries, nil
+}
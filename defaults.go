@@ -0,0 +1,47 @@
+package dotenv
+
+import (
+	"errors"
+	"reflect"
+)
+
+// UnmarshalWithDefaults copies the fields of defaults into dest and then
+// overlays the environment like MergeEnv, so defaults can be kept as typed
+// Go values instead of 'default' tags. defaults is a struct or a pointer to
+// one, usually of the same type as dest; fields are matched by name and
+// copied when their types are assignable, and other fields are left alone.
+func UnmarshalWithDefaults(dest interface{}, defaults interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("dest must be a non-nil pointer")
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("dest must be a pointer to a struct")
+	}
+
+	dv, err := structValue(defaults)
+	if err != nil {
+		return err
+	}
+
+	copyFields(rv, dv)
+	return MergeEnv(dest)
+}
+
+// copyFields sets each exported field of dst to the field of src with the
+// same name, when one exists and its type is assignable.
+func copyFields(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		structField := dst.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		value := src.FieldByName(structField.Name)
+		if value.IsValid() && value.Type().AssignableTo(structField.Type) {
+			dst.Field(i).Set(value)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package dotenv
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"strings"
@@ -12,6 +13,10 @@ import (
 // FilenameVariables defines the default files the package searches for.
 var FilenameVariables = []string{".env", ".env.local"}
 
+// NullValue is the value that sets a pointer field to nil in Unmarshal,
+// overriding any default. An empty value has the same effect.
+var NullValue = "null"
+
 // Collect iterates through the predefined filenames in FilenameVariables,
 // parses their content, and sets the resulting key-value pairs as
 // environment variables in the current process.
@@ -21,133 +26,199 @@ var FilenameVariables = []string{".env", ".env.local"}
 //   - Lines starting with "export ".
 //   - Comments starting with "#".
 //   - Basic handling of quoted values (via the internal quotes function).
+//
+// A path that names a directory is skipped like a missing file, so the
+// other files still load. Use Load or Loader.Collect to have it reported.
 func Collect() {
-	for _, filename := range FilenameVariables {
-		content, err := os.ReadFile(filename)
-		if err != nil {
-			continue
-		}
-
-		if len(content) <= 1 {
-			continue
-		}
-
-		for _, line := range strings.Split(string(content), "\n") {
-			if strings.HasPrefix(line, "export ") {
-				line = strings.TrimPrefix(line, "export")
-				line = strings.TrimSpace(line)
-			}
-
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-
-			key, value, found := strings.Cut(line, "=")
-			if !found {
-				continue
-			}
-
-			value = quotes(value)
+	loader := Loader{skipDirectories: true}
+	loader.Collect()
+}
 
-			os.Setenv(key, value)
-		}
-	}
+// AfterUnmarshaler is implemented by destination structs that compute
+// derived fields or validate themselves once every field has been set.
+// Unmarshal calls AfterUnmarshal last and returns its error.
+type AfterUnmarshaler interface {
+	AfterUnmarshal() error
 }
 
 // Unmarshal parses environment variables into the provided struct.
 // The struct must have 'env' tags defining which variables to map.
+// Values are read with Lookup, so the in-memory store takes precedence over
+// the process environment.
+//
+// The 'env' tag may list several names separated by "|", as in
+// `env:"DATABASE_URL|DB_URL"`. Marshal always writes the first name. Each
+// field's value is resolved in this order:
+//
+//  1. The names in tag order; the first set to a non-empty value wins.
+//  2. The 'defaultIf' tag, when its condition holds.
+//  3. The 'default' tag.
+//  4. The required check: a field tagged `required:"true"` is an error.
+//
+// The 'defaultIf' tag holds a "KEY=value:default" rule for defaults that
+// depend on another setting: `defaultIf:"SCHEME=https:443" default:"80"`
+// defaults to 443 when SCHEME is "https" and to 80 otherwise. The condition
+// is checked against the final value of an earlier field tagged with KEY,
+// including its default, or against the variable itself, and the
+// condition's value cannot contain ":".
+//
+// A map field tagged with 'envPrefix' is filled from every variable whose
+// name starts with the prefix. The rest of the name becomes the map key and
+// both key and value are converted to the map's types, so a field
+// `envPrefix:"LIMIT_"` of type map[string]int maps LIMIT_US=100 to
+// {"US": 100}.
+//
+// The 'requiredIf' and 'requiredUnless' tags make a field conditionally
+// required. Each holds a single KEY=value condition, compared against the
+// final value of the field tagged with that key, or against the variable
+// itself when no field declares it. `requiredUnless:"AUTH_MODE=none"`
+// requires the field unless AUTH_MODE is "none"; `requiredIf:"TLS=true"`
+// requires it only when TLS is "true". Conditions are checked after every
+// field has been set.
+//
+// Fields sharing a 'requiredGroup' tag, as in `requiredGroup:"auth"`, form
+// an either/or requirement: at least one of them must end up non-empty,
+// counting defaults. Groups are checked after the conditions.
+//
+// A struct field with no 'env' tag that does not implement
+// encoding.TextUnmarshaler is nested: its tagged fields are filled as if
+// they were declared in the outer struct, and errors name them as
+// "Outer.Field". Marshal writes the fields of each nested struct under a
+// "# [Name]" header naming the field, or the field's 'envSection' tag.
+//
+// A struct field tagged with 'split' receives one variable split on the
+// given separator, with each part assigned to the struct's exported fields
+// in order, so `env:"ADDR" split:":"` on struct{ Host string; Port int }
+// maps ADDR=localhost:8080 to {"localhost", 8080}. The last field receives
+// the remainder of the value, and fewer parts than fields is an error.
+//
+// Bool fields tagged with 'flag' share one variable holding a comma-separated
+// list of tokens, and are set to whether their token is listed: with
+// FLAGS=beta,newui, `env:"FLAGS" flag:"newui"` is true. Marshal writes the
+// tokens of every true field back into the one variable.
+//
+// The 'oneof' tag restricts a field to a "|"-separated list of values. With
+// `insensitive:"true"` the value matches regardless of case and is set using
+// the spelling from the list, so LEVEL=Info becomes "info".
+//
+// The ",expand" option, as in `env:"GREETING,expand"`, expands $VAR and
+// ${VAR} references in the field's value, including a default, against the
+// variables Unmarshal reads, even when the file was loaded without Expand.
+// Other fields keep such references literally.
+//
+// The 'source' tag limits where a field's value may come from.
+// `source:"file"` only accepts variables set by Load, Collect and the other
+// loading functions, or held in the in-memory store, so an inherited CI
+// variable cannot leak in; `source:"env"` only accepts variables the
+// process inherited. Decode applies the tag to its files and the process
+// environment. UnmarshalEnviron and UnmarshalFromMap cannot tell the two
+// apart and ignore it.
+//
+// Pointer fields are allocated when a value is set. A variable that is
+// present but empty or equal to NullValue leaves the pointer nil, even when
+// the field has a default; the default only applies when the variable is
+// absent.
 func Unmarshal(dest interface{}) error {
-	rv := reflect.ValueOf(dest)
-	if rv.Kind() != reflect.Ptr || rv.IsNil() {
-		return errors.New("dest must be a non-nil pointer")
-	}
+	return UnmarshalOptions{}.Unmarshal(dest)
+}
 
-	rv = rv.Elem()
-	if rv.Kind() != reflect.Struct {
-		return errors.New("dest must be a pointer to a struct")
+// UnmarshalEnviron fills dest like Unmarshal, but reads the variables from
+// environ, a slice of "KEY=VALUE" strings in the format of os.Environ,
+// instead of the process environment. When a key appears more than once,
+// the last entry wins.
+func UnmarshalEnviron(environ []string, dest interface{}) error {
+	vars := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		key, value, found := strings.Cut(entry, "=")
+		if found {
+			vars[key] = value
+		}
 	}
 
-	t := rv.Type()
-	for i := 0; i < rv.NumField(); i++ {
-		field := rv.Field(i)
-		fieldType := t.Field(i)
-
-		if !field.CanSet() {
-			continue
-		}
+	return UnmarshalOptions{}.unmarshal(dest, mapSource(vars))
+}
 
-		key := fieldType.Tag.Get("env")
-		required := fieldType.Tag.Get("required") == "true"
-		defaultValue := fieldType.Tag.Get("default")
+// UnmarshalFromMap fills dest like Unmarshal, but reads the variables only
+// from vars.
+func UnmarshalFromMap(vars map[string]string, dest interface{}) error {
+	return UnmarshalOptions{}.unmarshal(dest, mapSource(vars))
+}
 
-		if key == "" {
-			continue
-		}
+// UnmarshalAll fills dest like Unmarshal, but does not stop at the first
+// bad field. It returns every conversion and required-field error joined
+// with errors.Join, so all problems can be fixed at once, and sets every
+// field that did succeed.
+func UnmarshalAll(dest interface{}) error {
+	return UnmarshalOptions{AllErrors: true}.Unmarshal(dest)
+}
 
-		value, exists := os.LookupEnv(key)
-		if !exists || value == "" {
-			if defaultValue != "" {
-				value = defaultValue
-			} else if required {
-				return fmt.Errorf("error %s tag needs to be filled in", fieldType.Name)
-			} else {
-				continue
-			}
-		}
+// MergeEnv overlays environment variables on a struct that is already
+// populated, for example from a JSON or YAML base config. It behaves like
+// Unmarshal, but a field is only overwritten when its variable is set to a
+// non-empty value, so it is never zeroed. Defaults apply only to fields that
+// are still zero, and a required field is satisfied by its existing value.
+func MergeEnv(dest interface{}) error {
+	return UnmarshalOptions{merge: true}.Unmarshal(dest)
+}
 
-		if err := setField(field, value); err != nil {
-			return fmt.Errorf("error setting field %s: %w", fieldType.Name, err)
-		}
+// Decode reads filenames, or FilenameVariables when none are given, with
+// variable expansion enabled and fills dest from the result, without
+// modifying the process environment. As with Load, variables already set in
+// the process environment take precedence over the files.
+func Decode(dest interface{}, filenames ...string) error {
+	loader := Loader{Filenames: filenames, Expand: true}
+	vars, err := loader.Parse()
+	if err != nil {
+		return err
 	}
 
-	return nil
+	files := mapSource(vars)
+	return UnmarshalOptions{}.unmarshal(dest, source{
+		lookup: func(key string) (string, bool) {
+			if value, ok := os.LookupEnv(key); ok {
+				return value, true
+			}
+			return files.lookup(key)
+		},
+		environ: func() []string {
+			return append(files.environ(), os.Environ()...)
+		},
+		file: files.lookup,
+		env:  os.LookupEnv,
+	})
 }
 
 // Marshal converts a struct into a .env formatted byte slice.
 // It uses 'env' tags to define the keys.
 func Marshal(dest interface{}) ([]byte, error) {
-	rv := reflect.ValueOf(dest)
+	return MarshalOptions{}.Marshal(dest)
+}
 
-	if rv.Kind() == reflect.Ptr {
-		rv = rv.Elem()
-	}
+// MarshalTo writes the .env form of dest to w, like Marshal.
+func MarshalTo(w io.Writer, dest interface{}) error {
+	return MarshalOptions{}.MarshalTo(w, dest)
+}
 
-	if rv.Kind() != reflect.Struct {
-		return nil, errors.New("dest must be a struct or a pointer to a struct")
+// MarshalSlice writes the .env form of each element of slice to w, with sep
+// written between consecutive elements. The elements must be structs or
+// pointers to structs.
+func MarshalSlice(w io.Writer, slice interface{}, sep []byte) error {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return errors.New("slice must be a slice or an array")
 	}
 
-	var builder strings.Builder
-	t := rv.Type()
-
-	for i := 0; i < rv.NumField(); i++ {
-		field := rv.Field(i)
-		fieldType := t.Field(i)
-		if !field.CanInterface() {
-			continue
-		}
-
-		key := fieldType.Tag.Get("env")
-		if key == "" {
-			continue
-		}
-
-		value := fmt.Sprintf("%v", field.Interface())
-
-		if value == "" {
-			defaultValue := fieldType.Tag.Get("default")
-			if defaultValue != "" {
-				value = defaultValue
-			} else if fieldType.Tag.Get("required") == "true" {
-				return nil, fmt.Errorf("env %s for field %s is required", key, fieldType.Name)
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			if _, err := w.Write(sep); err != nil {
+				return err
 			}
 		}
 
-		if strings.Contains(value, " ") {
-			value = fmt.Sprintf(`"%s"`, value)
+		if err := MarshalTo(w, rv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("error marshaling element %d: %w", i, err)
 		}
-
-		builder.WriteString(fmt.Sprintf("%s=%s\n", key, value))
 	}
 
-	return []byte(builder.String()), nil
+	return nil
 }
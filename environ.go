@@ -0,0 +1,29 @@
+package dotenv
+
+import (
+	"bytes"
+	"os"
+)
+
+// ParseEnvironFile reads a NUL-separated list of KEY=VALUE entries, the
+// format of /proc/<pid>/environ on Linux, and returns the variables it
+// holds. Values are taken verbatim, with no quote or comment handling.
+// Entries without "=" are skipped, and when a key appears more than once
+// the last entry wins.
+func ParseEnvironFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	for _, entry := range bytes.Split(content, []byte{0}) {
+		key, value, found := bytes.Cut(entry, []byte("="))
+		if !found || len(key) == 0 {
+			continue
+		}
+		vars[string(key)] = string(value)
+	}
+
+	return vars, nil
+}
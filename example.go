@@ -0,0 +1,52 @@
+package dotenv
+
+import (
+	"bytes"
+	"errors"
+	"os"
+)
+
+// WriteExample regenerates the example file filename, such as .env.example,
+// from the keys declared by dest. Keys already present in the file keep
+// their current example value, new keys get their 'default' tag value or an
+// empty value, and keys the struct no longer declares are dropped. Keys are
+// written in field order. A missing file is created.
+func WriteExample(dest interface{}, filename string) error {
+	rv, err := structValue(dest)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]string)
+	content, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	loader := Loader{}
+	if _, err := loader.parse(filename, string(content), existing); err != nil {
+		return err
+	}
+
+	var pairs []pair
+	seen := make(map[string]bool)
+	for _, f := range cachedFields(rv.Type()) {
+		if f.key == "" || seen[f.key] {
+			continue
+		}
+		seen[f.key] = true
+
+		value, ok := existing[f.key]
+		if !ok {
+			value = f.defaultValue
+		}
+		pairs = append(pairs, pair{key: f.key, value: value})
+	}
+
+	var buf bytes.Buffer
+	if err := (MarshalOptions{}).writePairs(&buf, pairs); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, buf.Bytes(), 0o644)
+}
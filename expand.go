@@ -0,0 +1,83 @@
+package dotenv
+
+import "strings"
+
+// expand replaces variable references in value using lookup.
+//
+// It supports:
+//   - $VAR and ${VAR}, which resolve to the looked-up value or "".
+//   - ${VAR:-default}, which uses default when VAR is unset or empty.
+//   - \$ and $$, which produce a literal "$". "$$VAR" is therefore "$VAR"
+//     as plain text, not a reference.
+//
+// A "${" without a closing brace is kept as-is.
+func expand(value string, lookup func(string) (string, bool)) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		next := byte(0)
+		if i+1 < len(value) {
+			next = value[i+1]
+		}
+
+		switch {
+		case (c == '\\' || c == '$') && next == '$':
+			builder.WriteByte('$')
+			i++
+		case c == '$' && next == '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				builder.WriteString(value[i:])
+				return builder.String()
+			}
+
+			name, fallback, hasFallback := strings.Cut(value[i+2:i+2+end], ":-")
+			resolved, ok := lookup(name)
+			if hasFallback && (!ok || resolved == "") {
+				resolved = fallback
+			}
+
+			builder.WriteString(resolved)
+			i += 2 + end
+		case c == '$' && isNameStart(next):
+			j := i + 1
+			for j < len(value) && isNameChar(value[j]) {
+				j++
+			}
+
+			resolved, _ := lookup(value[i+1 : j])
+			builder.WriteString(resolved)
+			i = j - 1
+		default:
+			builder.WriteByte(c)
+		}
+	}
+
+	return builder.String()
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// isName reports whether name is a valid POSIX variable name.
+func isName(name string) bool {
+	if name == "" || !isNameStart(name[0]) {
+		return false
+	}
+
+	for i := 1; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
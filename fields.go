@@ -0,0 +1,244 @@
+package dotenv
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// field describes a struct field that is mapped to an environment variable.
+type field struct {
+	index        []int
+	name         string
+	key          string
+	aliases      []string
+	prefix       string
+	pointer      bool
+	format       string
+	split        string
+	flag         string
+	source       string
+	expand       bool
+	required     bool
+	optional     bool
+	defaultValue string
+	comment      string
+
+	// oneof lists the allowed values, if any. With insensitive set, a value
+	// matches regardless of case and is replaced by the listed spelling.
+	oneof       []string
+	insensitive bool
+
+	// requiredIf and requiredUnless hold a "KEY=value" condition under
+	// which the field is, or is not, required.
+	requiredIf     string
+	requiredUnless string
+
+	// requiredGroup names a group of fields of which at least one must be
+	// set.
+	requiredGroup string
+
+	// defaultIf holds a "KEY=value:default" rule: the default used when
+	// KEY has the given value.
+	defaultIf string
+
+	// section names the nested struct the field belongs to, from its
+	// 'envSection' tag or field name. Marshal writes it as a header.
+	section string
+}
+
+// fieldCache holds the parsed fields of each struct type seen by
+// Unmarshal and Marshal, keyed by reflect.Type.
+var fieldCache sync.Map
+
+// cachedFields returns the tagged, exported fields of the struct type t,
+// including those of nested structs. Map fields tagged with 'envPrefix' are
+// returned with an empty key.
+// The tags are parsed once per type and reused on later calls.
+func cachedFields(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		// An untagged struct field is nested: its own tagged fields are
+		// read as if declared here, grouped under a section named by its
+		// 'envSection' tag or field name. Embedded structs get no section.
+		if isNested(structField) {
+			section := structField.Tag.Get("envSection")
+			if section == "" && !structField.Anonymous {
+				section = structField.Name
+			}
+
+			for _, nested := range cachedFields(structField.Type) {
+				nested.index = append([]int{i}, nested.index...)
+				nested.name = structField.Name + "." + nested.name
+				if nested.section == "" {
+					nested.section = section
+				}
+				fields = append(fields, nested)
+			}
+			continue
+		}
+
+		// The 'env' tag may list alternative names separated by "|". The
+		// first name is the primary key used by Marshal. Options such as
+		// ",expand" follow the names.
+		tag, options, _ := strings.Cut(structField.Tag.Get("env"), ",")
+		names := strings.Split(tag, "|")
+		key := names[0]
+		prefix := structField.Tag.Get("envPrefix")
+		if structField.Type.Kind() != reflect.Map {
+			prefix = ""
+		} else if prefix != "" {
+			key = ""
+		}
+
+		if key == "" && prefix == "" {
+			continue
+		}
+
+		// envDefault is accepted for compatibility with caarlos0/env.
+		// The 'default' tag takes precedence when both are set.
+		defaultValue := structField.Tag.Get("default")
+		if defaultValue == "" {
+			defaultValue = structField.Tag.Get("envDefault")
+		}
+
+		var oneof []string
+		if tag := structField.Tag.Get("oneof"); tag != "" {
+			oneof = strings.Split(tag, "|")
+		}
+
+		fields = append(fields, field{
+			index:        []int{i},
+			name:         structField.Name,
+			key:          key,
+			aliases:      names[1:],
+			prefix:       prefix,
+			pointer:      structField.Type.Kind() == reflect.Ptr,
+			format:       structField.Tag.Get("format"),
+			split:        structField.Tag.Get("split"),
+			flag:         structField.Tag.Get("flag"),
+			source:       structField.Tag.Get("source"),
+			expand:       slices.Contains(strings.Split(options, ","), "expand"),
+			required:     structField.Tag.Get("required") == "true",
+			optional:     structField.Tag.Get("optional") == "true",
+			defaultValue: defaultValue,
+			comment:      structField.Tag.Get("comment"),
+
+			oneof:       oneof,
+			insensitive: structField.Tag.Get("insensitive") == "true",
+
+			requiredIf:     structField.Tag.Get("requiredIf"),
+			requiredUnless: structField.Tag.Get("requiredUnless"),
+			requiredGroup:  structField.Tag.Get("requiredGroup"),
+			defaultIf:      structField.Tag.Get("defaultIf"),
+		})
+	}
+
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]field)
+}
+
+// isNested reports whether structField is a struct whose fields are read
+// as part of the enclosing struct: a struct value without an 'env' or
+// 'envPrefix' tag that does not decode itself, as time.Time does.
+func isNested(structField reflect.StructField) bool {
+	t := structField.Type
+	return t.Kind() == reflect.Struct &&
+		structField.Tag.Get("env") == "" && structField.Tag.Get("envPrefix") == "" &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// lookup returns the value of the first of the field's names that is set
+// to a non-empty value in src, trying the primary key before the aliases in
+// tag order. When none is, it reports whether any name is present at all.
+// A 'source' tag limits the lookup to files or to the process environment.
+func (f field) lookup(src source) (string, bool) {
+	lookup := src.restrict(f.source)
+	value, exists := lookup(f.key)
+	if value != "" {
+		return value, true
+	}
+
+	for _, alias := range f.aliases {
+		aliasValue, aliasExists := lookup(alias)
+		if aliasValue != "" {
+			return aliasValue, true
+		}
+		exists = exists || aliasExists
+	}
+
+	return "", exists
+}
+
+// choose checks value against the field's 'oneof' list and returns the
+// listed spelling of it. Fields without the tag accept any value.
+func (f field) choose(value string) (string, error) {
+	if len(f.oneof) == 0 {
+		return value, nil
+	}
+
+	for _, allowed := range f.oneof {
+		if value == allowed || (f.insensitive && strings.EqualFold(value, allowed)) {
+			return allowed, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of %s", value, strings.Join(f.oneof, "|"))
+}
+
+// supported reports whether values of type t can be decoded into the field.
+func (f field) supported(t reflect.Type) bool {
+	switch {
+	case f.format == "json":
+		return true
+	case f.format == "pathlist":
+		return isStrings(t)
+	case f.flag != "":
+		return t.Kind() == reflect.Bool
+	case f.prefix != "":
+		return supportedType(t.Key()) && supportedType(t.Elem())
+	case f.split != "":
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && !supportedType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return supportedType(t)
+	}
+}
+
+// supportedType reports whether setField can decode into type t.
+func supportedType(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return supportedType(t.Elem())
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+type benchConfig struct {
+	Host  string  `env:"BENCH_HOST" default:"localhost"`
+	Port  int     `env:"BENCH_PORT" required:"true"`
+	Debug bool    `env:"BENCH_DEBUG"`
+	Rate  float64 `env:"BENCH_RATE|BENCH_RATE_LIMIT" default:"1.5"`
+}
+
+// BenchmarkUnmarshalFieldCache compares Unmarshal with the parsed tags
+// reused from fieldCache against parsing them again on every call.
+func BenchmarkUnmarshalFieldCache(b *testing.B) {
+	b.Setenv("BENCH_PORT", "8080")
+	b.Setenv("BENCH_DEBUG", "true")
+	t := reflect.TypeOf(benchConfig{})
+
+	run := func(b *testing.B, clear bool) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if clear {
+				fieldCache.Delete(t)
+			}
+
+			var cfg benchConfig
+			if err := Unmarshal(&cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) { run(b, false) })
+	b.Run("uncached", func(b *testing.B) { run(b, true) })
+}
+
+// BenchmarkMarshalFieldCache is BenchmarkUnmarshalFieldCache for Marshal.
+func BenchmarkMarshalFieldCache(b *testing.B) {
+	cfg := benchConfig{Host: "localhost", Port: 8080, Debug: true, Rate: 1.5}
+	t := reflect.TypeOf(cfg)
+
+	run := func(b *testing.B, clear bool) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if clear {
+				fieldCache.Delete(t)
+			}
+
+			if _, err := Marshal(&cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) { run(b, false) })
+	b.Run("uncached", func(b *testing.B) { run(b, true) })
+}
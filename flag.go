@@ -0,0 +1,33 @@
+package dotenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// setFlagField sets the bool field to whether flag appears in value, a
+// comma-separated list of tokens. Spaces around tokens are ignored.
+func setFlagField(field reflect.Value, value, flag string) error {
+	if field.Kind() != reflect.Bool {
+		return fmt.Errorf("flag requires a bool field, got %s", field.Type())
+	}
+
+	for _, token := range strings.Split(value, ",") {
+		if strings.TrimSpace(token) == flag {
+			field.SetBool(true)
+			return nil
+		}
+	}
+
+	field.SetBool(false)
+	return nil
+}
+
+// addFlag appends flag to the comma-separated list.
+func addFlag(list, flag string) string {
+	if list == "" {
+		return flag
+	}
+	return list + "," + flag
+}
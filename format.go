@@ -0,0 +1,202 @@
+package dotenv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// setFormatted converts value according to the field's 'format' tag before
+// setting it. An empty format uses setField unchanged.
+//
+// Supported formats:
+//   - "boolint": integer fields also accept boolean words, so "true" sets 1
+//     and "false" sets 0.
+//   - "base64": []byte fields are decoded from standard base64.
+//   - "percent": float fields read a percentage, so "10%" sets 0.1. The
+//     trailing "%" is optional and the number is always divided by 100.
+//   - "auto": time.Time fields accept the first of timeLayouts that parses,
+//     or a Unix timestamp in seconds.
+//   - "char": rune (int32) fields take the first character of the value, so
+//     "A" sets 'A' instead of failing to parse as a number.
+//   - "pathlist": []string fields are split on os.PathListSeparator, ":"
+//     on Unix and ";" on Windows, like the PATH variable.
+//   - "json": the value is decoded with json.Unmarshal, so a struct, map or
+//     slice field can be filled from a single variable.
+func setFormatted(field reflect.Value, value, format string) error {
+	if format != "" && field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFormatted(elem.Elem(), value, format); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	switch format {
+	case "":
+		return setField(field, value)
+	case "boolint":
+		if !isInt(field.Kind()) {
+			return fmt.Errorf("format %s requires an integer field, got %s", format, field.Type())
+		}
+
+		if b, err := strconv.ParseBool(value); err == nil {
+			if b {
+				field.SetInt(1)
+			} else {
+				field.SetInt(0)
+			}
+			return nil
+		}
+		return setField(field, value)
+	case "base64":
+		if !isBytes(field.Type()) {
+			return fmt.Errorf("format %s requires a []byte field, got %s", format, field.Type())
+		}
+
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("cannot decode value as base64: %w", err)
+		}
+		field.SetBytes(b)
+		return nil
+	case "percent":
+		if !isFloat(field.Kind()) {
+			return fmt.Errorf("format %s requires a float field, got %s", format, field.Type())
+		}
+
+		if err := setField(field, strings.TrimSuffix(value, "%")); err != nil {
+			return err
+		}
+		field.SetFloat(field.Float() / 100)
+		return nil
+	case "auto":
+		if field.Type() != timeType {
+			return fmt.Errorf("format %s requires a time.Time field, got %s", format, field.Type())
+		}
+
+		t, err := parseTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case "char":
+		if field.Kind() != reflect.Int32 {
+			return fmt.Errorf("format %s requires a rune field, got %s", format, field.Type())
+		}
+
+		r, size := utf8.DecodeRuneInString(value)
+		if r == utf8.RuneError && size <= 1 {
+			return fmt.Errorf("cannot read %q as a character", value)
+		}
+		field.SetInt(int64(r))
+		return nil
+	case "pathlist":
+		if !isStrings(field.Type()) {
+			return fmt.Errorf("format %s requires a []string field, got %s", format, field.Type())
+		}
+
+		field.Set(reflect.ValueOf(filepath.SplitList(value)).Convert(field.Type()))
+		return nil
+	case "json":
+		if err := json.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
+			return fmt.Errorf("cannot decode value as json: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// formatValue returns the string form of a field value written by Marshal,
+// reversing setFormatted.
+func formatValue(fv reflect.Value, format string) (string, error) {
+	if format == "json" {
+		b, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return "", fmt.Errorf("cannot encode value as json: %w", err)
+		}
+		return string(b), nil
+	}
+
+	if format == "auto" && fv.Type() == timeType {
+		return fv.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+
+	if format == "pathlist" && isStrings(fv.Type()) {
+		return strings.Join(fv.Convert(reflect.TypeOf([]string(nil))).Interface().([]string), string(os.PathListSeparator)), nil
+	}
+
+	if format == "char" && fv.Kind() == reflect.Int32 {
+		return string(rune(fv.Int())), nil
+	}
+
+	if format == "percent" && isFloat(fv.Kind()) {
+		// Round away the error of the multiplication, so 0.1 is written as
+		// 10% rather than 10.000000000000002%.
+		percent := math.Round(fv.Float()*100*1e9) / 1e9
+		return strconv.FormatFloat(percent, 'f', -1, 64) + "%", nil
+	}
+
+	if isBytes(fv.Type()) {
+		if format == "base64" {
+			return base64.StdEncoding.EncodeToString(fv.Bytes()), nil
+		}
+		return string(fv.Bytes()), nil
+	}
+
+	return fmt.Sprintf("%v", fv.Interface()), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayouts are the layouts tried in order by the "auto" format, before
+// falling back to a Unix timestamp.
+var timeLayouts = []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05"}
+
+// parseTime parses value with the first of timeLayouts that accepts it, or
+// as a Unix timestamp in seconds.
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse %q as time: tried layouts %s and a Unix timestamp", value, strings.Join(timeLayouts, ", "))
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func isStrings(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem() == reflect.TypeOf("")
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isInt(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
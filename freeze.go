@@ -0,0 +1,89 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// loaded records the variables the package has set in the process
+// environment with the values it set, and the values captured for them by
+// Freeze.
+var loaded = struct {
+	sync.Mutex
+	keys   map[string]string
+	frozen map[string]*string
+}{keys: make(map[string]string)}
+
+// markLoaded records that key was set to value in the process environment.
+func markLoaded(key, value string) {
+	loaded.Lock()
+	defer loaded.Unlock()
+
+	loaded.keys[key] = value
+}
+
+// isLoaded reports whether key still holds the value one of the package's
+// loading functions set it to. A key changed since, for example by
+// os.Setenv, no longer counts as loaded.
+func isLoaded(key string) bool {
+	loaded.Lock()
+	defer loaded.Unlock()
+
+	value, ok := loaded.keys[key]
+	if !ok {
+		return false
+	}
+
+	current, ok := os.LookupEnv(key)
+	return ok && current == value
+}
+
+// Freeze snapshots the current values of every variable the package has set
+// in the process environment through Collect, Load, Overload and the other
+// loading functions. VerifyUnchanged later reports any that drifted. Calling
+// Freeze again replaces the snapshot.
+func Freeze() {
+	loaded.Lock()
+	defer loaded.Unlock()
+
+	loaded.frozen = make(map[string]*string, len(loaded.keys))
+	for key := range loaded.keys {
+		if value, ok := os.LookupEnv(key); ok {
+			loaded.frozen[key] = &value
+		} else {
+			loaded.frozen[key] = nil
+		}
+	}
+}
+
+// VerifyUnchanged checks that every variable snapshot by Freeze still holds
+// its frozen value, and returns an error listing, in order, those that were
+// changed or unset since. Go cannot prevent os.Setenv calls, so this is an
+// audit for tests or periodic checks rather than a lock.
+func VerifyUnchanged() error {
+	loaded.Lock()
+	defer loaded.Unlock()
+
+	if loaded.frozen == nil {
+		return errors.New("Freeze has not been called")
+	}
+
+	var drifted []string
+	for key, frozen := range loaded.frozen {
+		value, ok := os.LookupEnv(key)
+		if ok != (frozen != nil) || (ok && value != *frozen) {
+			drifted = append(drifted, key)
+		}
+	}
+
+	if len(drifted) > 0 {
+		sort.Strings(drifted)
+		return fmt.Errorf("variables changed since Freeze: %s", strings.Join(drifted, ", "))
+	}
+
+	return nil
+}
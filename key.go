@@ -0,0 +1,40 @@
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// UnmarshalKey reads the variable key with Lookup and converts it to T with
+// the same rules Unmarshal applies to a field of that type: strings, bools,
+// integers, floats, time.Duration, []byte, pointers and types implementing
+// encoding.TextUnmarshaler. It returns an error when key is not set.
+//
+//	timeout, err := dotenv.UnmarshalKey[time.Duration]("TIMEOUT")
+func UnmarshalKey[T any](key string) (T, error) {
+	var value T
+
+	raw, ok := Lookup(key)
+	if !ok {
+		return value, fmt.Errorf("env %s is not set", key)
+	}
+
+	if err := setField(reflect.ValueOf(&value).Elem(), raw); err != nil {
+		return value, fmt.Errorf("error setting %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Environ returns a new T, which must be a struct type, filled like Unmarshal
+// but only from the process environment: no files are read and the
+// in-memory store is ignored. It suits code that has already called Load or
+// Collect and just wants its typed config.
+//
+//	cfg, err := dotenv.Environ[Config]()
+func Environ[T any]() (T, error) {
+	var value T
+	err := UnmarshalOptions{}.unmarshal(&value, source{lookup: os.LookupEnv, environ: os.Environ})
+	return value, err
+}
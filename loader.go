@@ -0,0 +1,814 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Loader reads environment files with configurable parsing rules.
+// The zero value reads FilenameVariables using the same rules as Collect.
+type Loader struct {
+	// Filenames lists the files to read. Files named here are required: a
+	// file that cannot be read is an error. When empty, FilenameVariables is
+	// used instead and missing files are skipped, since those defaults are
+	// only read when present. A path that names a directory is always an
+	// error. The name "-" reads standard input, so Load("-") consumes
+	// piped configuration. A file whose extension has a Decoder registered
+	// with RegisterDecoder is read by that decoder.
+	Filenames []string
+
+	// Expand enables $VAR, ${VAR} and ${VAR:-default} references in
+	// unquoted and double-quoted values. References resolve against the
+	// variables read so far and then the process environment. A literal
+	// dollar sign is written as \$ or $$.
+	Expand bool
+
+	// ExpandWithOSSemantics expands values with os.Expand instead of the
+	// package's own rules, using the same lookup order as Expand. It takes
+	// precedence over Expand. Unlike Expand, it has no ${VAR:-default}
+	// syntax and no escapes: \$ is kept as a backslash followed by the
+	// reference, and $$ is looked up as a variable named "$".
+	ExpandWithOSSemantics bool
+
+	// CommentChar starts full-line and inline comments. It defaults to '#';
+	// set it to ';' to read ini-style files.
+	CommentChar byte
+
+	// TemplateValues renders each unquoted and double-quoted value as a
+	// text/template, with the variables read so far and the process
+	// environment as data: GREETING=Hello {{.USER}}. It runs after
+	// expansion. Referencing an undefined variable is an error.
+	TemplateValues bool
+
+	// ValidateNames rejects keys that are not valid POSIX variable names,
+	// matching [A-Za-z_][A-Za-z0-9_]*. Names such as 123KEY or MY-KEY are
+	// accepted by os.Setenv but cannot be referenced by most shells.
+	ValidateNames bool
+
+	// CollapseWhitespace replaces each run of whitespace inside an unquoted
+	// value with a single space, so KEY=a    b becomes "a b". Quoted values
+	// are left untouched.
+	CollapseWhitespace bool
+
+	// StripPrefix is removed from the start of every key that has it, so
+	// with StripPrefix "MYAPP_" the line MYAPP_PORT=8080 sets PORT. Keys
+	// without the prefix are read unchanged.
+	StripPrefix string
+
+	// AllowFileRefs reads an unquoted value of the form @PATH from the file
+	// at PATH, so TLS_CERT=@/etc/ssl/cert.pem holds the certificate itself.
+	// One trailing newline is removed from the content, and a missing file
+	// is an error. Relative paths are resolved from the working directory,
+	// and the path is expanded first when Expand is set. Quote the value,
+	// as in "@handle", to keep a leading @ literally.
+	AllowFileRefs bool
+
+	// AllowIncludes honors lines such as "@include base.env", which read
+	// another file at that point, resolved from the including file's
+	// directory, so later lines override it. It is off by default for
+	// safety, and never applies to standard input, readers, arguments or
+	// URLs, whose content must not open local files. Without it such a
+	// line is not an assignment.
+	AllowIncludes bool
+
+	// SecretResolvers resolves values that reference a secret manager. An
+	// unquoted value starting with one of the map's keys, such as
+	// "arn:aws:secretsmanager:" or "gcp://", is passed whole to that
+	// resolver and replaced by its result; when several keys match, the
+	// longest wins. An error from the resolver is a ParseError. Quote the
+	// value to keep it literally. The package ships no resolvers: plug in
+	// the cloud SDK of your choice.
+	SecretResolvers map[string]SecretResolver
+
+	// MaxValueLen, when positive, is the longest value in bytes that may be
+	// read. A longer value, such as a binary blob pasted by mistake, is an
+	// error naming its key. The limit applies after expansion and file
+	// references. Zero means no limit.
+	MaxValueLen int
+
+	// OnSet, when non-nil, is called by Collect after each variable is set,
+	// with the file the value came from. It can be used to count, log or
+	// audit loaded variables.
+	OnSet func(key, value, file string)
+
+	// Setenv replaces os.Setenv as the function Collect uses to set each
+	// variable, so tests can capture the variables instead, or callers can
+	// redirect them elsewhere. Whether a variable is already present is
+	// still checked with os.LookupEnv.
+	Setenv func(key, value string) error
+
+	// Strict turns input that is normally read leniently into a ParseError:
+	// a line that is not an assignment or has an empty key, as in =value,
+	// a double-quoted value that is never closed, text after a closing
+	// quote other than an inline comment, as in KEY="value"extra, a
+	// malformed \x or \u escape in a double-quoted value, and a key
+	// assigned twice in the same file. Without it such lines are skipped,
+	// a value whose quotes do not match is read literally, quotes included,
+	// the escape is kept literally, and the last assignment wins.
+	//
+	// A file may also enable Strict and Expand for itself with a first line
+	// such as "#!dotenv v1 strict expand".
+	Strict bool
+
+	// DetectCaseCollisions makes Strict also reject a key that differs only
+	// in case from an earlier key in the same file or from a variable in
+	// the process environment, such as Path next to PATH. Such keys collide
+	// on case-insensitive platforms like Windows and are usually mistakes.
+	// Validate reports them too. It has no effect without Strict.
+	DetectCaseCollisions bool
+
+	// LintQuotes makes Validate also report values that look misquoted, such
+	// as a single-quoted value that is never closed, a stray quote of the
+	// other kind inside a quoted value, as in 'single"double', or an unquoted
+	// value ending in a quote. These values still load normally; the option
+	// only affects Validate.
+	LintQuotes bool
+
+	// warnings, when non-nil, collects the LintQuotes findings of parse.
+	warnings *[]error
+
+	// included, when non-nil, collects the paths of the files read through
+	// @include lines.
+	included *[]string
+
+	// skipDirectories makes a path that names a directory read as empty
+	// instead of being an error. It is set by the package-level Collect,
+	// which has no error to report it with.
+	skipDirectories bool
+
+	// including lists the absolute paths of the files whose @include lines
+	// led to the file being parsed, outermost first.
+	including []string
+}
+
+// SecretResolver returns the secret referenced by ref, the whole value of a
+// variable such as "arn:aws:secretsmanager:us-east-1:123:secret:db".
+type SecretResolver func(ref string) (string, error)
+
+// ParseError reports a problem at a specific line of an environment file.
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Entry is a single variable assignment read from a file.
+type Entry struct {
+	Key   string
+	Value string
+
+	// Comment is the inline comment that followed the value, without the
+	// comment character and surrounding spaces. It is empty when there is none.
+	Comment string
+
+	// File and Line locate the assignment. Line starts at 1.
+	File string
+	Line int
+}
+
+// Parse reads the loader's files in order and returns the resulting
+// variables without modifying the process environment. Later files
+// override earlier ones.
+func (l *Loader) Parse() (map[string]string, error) {
+	entries, err := l.ParseWithSource()
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		vars[entry.Key] = entry.Value
+	}
+
+	return vars, nil
+}
+
+// ParseWithSource reads the loader's files like Parse, but returns every
+// assignment in the order it was read, together with its inline comment
+// and the file and line it came from.
+func (l *Loader) ParseWithSource() ([]Entry, error) {
+	vars := make(map[string]string)
+	var entries []Entry
+
+	for _, filename := range l.filenames() {
+		content, err := l.readFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(content) <= 1 {
+			continue
+		}
+
+		parsed, err := l.parseFile(filename, content, vars)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, parsed...)
+	}
+
+	return entries, nil
+}
+
+// Validate parses every file like ParseWithSource without modifying the
+// process environment. It returns the first problem found in each file,
+// joined with errors.Join, or nil when every file is well-formed. Files are
+// always parsed in Strict mode, so input Parse would read leniently is
+// reported too. With LintQuotes set, every likely-misquoted value is
+// reported as well. It is meant for startup checks and "config lint" commands.
+func (l *Loader) Validate() error {
+	var warnings []error
+	lint := *l
+	lint.Strict = true
+	if l.LintQuotes {
+		lint.warnings = &warnings
+	}
+
+	vars := make(map[string]string)
+	var errs []error
+	for _, filename := range l.filenames() {
+		content, err := l.readFile(filename)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if _, err := lint.parseFile(filename, content, vars); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(append(warnings, errs...)...)
+}
+
+// readFile returns the content of filename, or of standard input when
+// filename is "-". A missing default file reads as empty, while a missing
+// file named in Filenames is an error.
+func (l *Loader) readFile(filename string) (string, error) {
+	if filename == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		return string(content), err
+	}
+
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		if l.skipDirectories {
+			return "", nil
+		}
+		return "", fmt.Errorf("%s is a directory, not an environment file", filename)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if len(l.Filenames) > 0 {
+			return "", err
+		}
+		return "", nil
+	}
+
+	return string(content), nil
+}
+
+// ParseReaders parses readers in order with the loader's rules, ignoring
+// Filenames. Later readers override earlier ones, so a baseline can be
+// layered under an override. Entries read from readers[i] are reported as
+// coming from "reader i".
+func (l *Loader) ParseReaders(readers ...io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+	noIncludes := *l
+	noIncludes.AllowIncludes = false
+
+	for i, r := range readers {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := noIncludes.parse(fmt.Sprintf("reader %d", i), string(content), vars); err != nil {
+			return nil, err
+		}
+	}
+
+	return vars, nil
+}
+
+// ParseArgs parses command-line assignments such as those given by repeated
+// --env KEY=VALUE flags, in the KEY=VALUE form of os.Environ, with the same
+// quoting, comment and expansion rules as a line of a file, so
+// `NAME="two words"` yields two words. Later arguments override earlier
+// ones and may reference them. Each argument is parsed in Strict mode, and
+// one that is not an assignment is a ParseError naming "argument i".
+func (l *Loader) ParseArgs(args []string) (map[string]string, error) {
+	strict := *l
+	strict.Strict = true
+	strict.AllowIncludes = false
+
+	vars := make(map[string]string, len(args))
+	for i, arg := range args {
+		name := fmt.Sprintf("argument %d", i)
+		if !strings.Contains(arg, "=") {
+			return nil, &ParseError{File: name, Line: 1, Err: fmt.Errorf("%q is not a KEY=VALUE assignment", arg)}
+		}
+
+		if _, err := strict.parse(name, arg, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	return vars, nil
+}
+
+// ParseArgs parses args like Loader.ParseArgs with variable expansion
+// enabled, so an argument can reference ${HOME} or an earlier argument.
+func ParseArgs(args []string) (map[string]string, error) {
+	loader := Loader{Expand: true}
+	return loader.ParseArgs(args)
+}
+
+// CollectReaders parses readers like Loader.ParseReaders, with later readers
+// overriding earlier ones, and sets the result in the process environment.
+func CollectReaders(readers ...io.Reader) error {
+	loader := Loader{}
+	vars, err := loader.ParseReaders(readers...)
+	if err != nil {
+		return err
+	}
+
+	return loader.applyVars(vars, nil, true)
+}
+
+// Collect reads the loader's files and sets the resulting key-value pairs
+// as environment variables in the current process, overwriting any
+// existing values.
+func (l *Loader) Collect() error {
+	return l.apply(true)
+}
+
+// apply reads the loader's files and sets the resulting variables. When
+// overwrite is false, variables already present in the process environment
+// are left untouched.
+func (l *Loader) apply(overwrite bool) error {
+	entries, err := l.ParseWithSource()
+	if err != nil {
+		return err
+	}
+
+	vars := make(map[string]string, len(entries))
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		vars[entry.Key] = entry.Value
+		files[entry.Key] = entry.File
+	}
+
+	return l.applyVars(vars, files, overwrite)
+}
+
+// applyVars sets vars with the loader's setter, skipping variables that are
+// already present in the process environment unless overwrite is true.
+// files maps each key to the file it came from and may be nil.
+func (l *Loader) applyVars(vars, files map[string]string, overwrite bool) error {
+	setenv := l.Setenv
+	if setenv == nil {
+		setenv = func(key, value string) error {
+			if err := os.Setenv(key, value); err != nil {
+				return err
+			}
+			markLoaded(key, value)
+			return nil
+		}
+	}
+
+	for key, value := range vars {
+		if !overwrite {
+			if _, exists := os.LookupEnv(key); exists {
+				continue
+			}
+		}
+
+		if err := setenv(key, value); err != nil {
+			return err
+		}
+
+		if l.OnSet != nil {
+			l.OnSet(key, value, files[key])
+		}
+	}
+
+	return nil
+}
+
+// Load reads filenames, or FilenameVariables when none are given, and sets
+// each variable that is not already present in the process environment.
+// Values exported by the shell therefore take precedence over the files.
+//
+// Files named explicitly must exist, while the FilenameVariables defaults
+// are skipped when missing.
+func Load(filenames ...string) error {
+	loader := Loader{Filenames: filenames}
+	return loader.apply(false)
+}
+
+// Overload reads filenames like Load, but overwrites variables that are
+// already present in the process environment.
+func Overload(filenames ...string) error {
+	loader := Loader{Filenames: filenames}
+	return loader.apply(true)
+}
+
+// expand resolves variable references in value according to the loader's
+// expansion options.
+func (l *Loader) expand(value string, vars map[string]string) string {
+	lookup := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+
+	switch {
+	case l.ExpandWithOSSemantics:
+		return os.Expand(value, func(name string) string {
+			v, _ := lookup(name)
+			return v
+		})
+	case l.Expand:
+		return expand(value, lookup)
+	default:
+		return value
+	}
+}
+
+func (l *Loader) commentChar() byte {
+	if l.CommentChar == 0 {
+		return '#'
+	}
+	return l.CommentChar
+}
+
+func (l *Loader) filenames() []string {
+	if len(l.Filenames) > 0 {
+		return l.Filenames
+	}
+	return FilenameVariables
+}
+
+// render executes value as a template when TemplateValues is set.
+func (l *Loader) render(value string, vars map[string]string) (string, error) {
+	if !l.TemplateValues || !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New("value").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	data := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, v, _ := strings.Cut(entry, "=")
+		data[key] = v
+	}
+	for key, v := range vars {
+		data[key] = v
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// parse reads the lines of content into vars and returns the entries found.
+func (l *Loader) parse(filename, content string, vars map[string]string) ([]Entry, error) {
+	var entries []Entry
+	lines := strings.Split(content, "\n")
+
+	start := 0
+	if words := strings.Fields(lines[0]); len(words) > 0 && words[0] == directive {
+		withDirective, err := l.withDirective(words[1:])
+		if err != nil {
+			return nil, &ParseError{File: filename, Line: 1, Err: err}
+		}
+		l = withDirective
+		start = 1
+	}
+
+	comment := l.commentChar()
+	seen := make(map[string]int)
+	folded := l.foldedEnviron()
+	for i := start; i < len(lines); i++ {
+		// Indentation and the carriage return of a CRLF line ending are
+		// not part of the line, so blank lines and indented comments are
+		// recognized in files edited on Windows too.
+		line := strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t")
+		lineNumber := i + 1
+
+		if strings.HasPrefix(line, "export ") {
+			line = strings.TrimPrefix(line, "export")
+			line = strings.TrimSpace(line)
+		}
+
+		if line == "" || line[0] == comment {
+			continue
+		}
+
+		for continuesLine(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + lines[i]
+		}
+
+		if target, ok := cutInclude(line); ok && l.AllowIncludes && filename != "-" {
+			included, err := l.include(filename, lineNumber, target, vars)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, included...)
+			continue
+		}
+
+		key, raw, found := cutKey(line)
+		if !found {
+			if l.Strict {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: errors.New("line is not a KEY=VALUE assignment")}
+			}
+			continue
+		}
+
+		// A line such as =value has no key. os.Setenv would reject it with
+		// an error naming neither the file nor the line, so it is skipped
+		// here, or reported in strict mode.
+		if key == "" {
+			if l.Strict {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: errors.New("empty key")}
+			}
+			continue
+		}
+		if key != l.StripPrefix {
+			key = strings.TrimPrefix(key, l.StripPrefix)
+		}
+
+		// A double-quoted value whose closing quote is not on this line
+		// continues onto the following lines, keeping the line breaks. When
+		// no later line closes it, or text follows the closing quote, the
+		// line is read on its own.
+		if unterminatedQuote(raw) {
+			if j := closingLine(lines, i+1, comment); j >= 0 {
+				raw += "\n" + strings.Join(lines[i+1:j+1], "\n")
+				i = j
+			}
+
+			if l.Strict && unterminatedQuote(raw) {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: errors.New("unterminated quoted value")}
+			}
+		}
+
+		if l.Strict && trailingText(raw, comment) {
+			return nil, &ParseError{File: filename, Line: lineNumber, Err: errors.New("unexpected text after closing quote")}
+		}
+
+		if l.Strict {
+			if err := invalidEscape(raw); err != nil {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: err}
+			}
+		}
+
+		if l.warnings != nil {
+			if err := misquoted(raw, comment); err != nil {
+				*l.warnings = append(*l.warnings, &ParseError{File: filename, Line: lineNumber, Err: err})
+			}
+		}
+
+		if l.Strict && l.DetectCaseCollisions {
+			if other, ok := folded[strings.ToLower(key)]; ok && other != key {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: fmt.Errorf("key %s differs only in case from %s", key, other)}
+			}
+			folded[strings.ToLower(key)] = key
+		}
+
+		if first, ok := seen[key]; ok && l.Strict {
+			return nil, &ParseError{File: filename, Line: lineNumber, Err: fmt.Errorf("duplicate key %s, first set on line %d", key, first)}
+		}
+		seen[key] = lineNumber
+
+		if l.ValidateNames && !isName(key) {
+			return nil, &ParseError{File: filename, Line: lineNumber, Err: fmt.Errorf("invalid variable name %q", key)}
+		}
+
+		value := quotes(raw, comment)
+
+		if l.CollapseWhitespace && !isQuoted(raw, comment) {
+			value = strings.Join(strings.Fields(value), " ")
+		}
+
+		if quoteOf(raw, comment) != '\'' {
+			value = l.expand(value, vars)
+
+			rendered, err := l.render(value, vars)
+			if err != nil {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: err}
+			}
+			value = rendered
+		}
+
+		if l.AllowFileRefs && !isQuoted(raw, comment) && strings.HasPrefix(value, "@") {
+			content, err := readFileRef(value[1:])
+			if err != nil {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: err}
+			}
+			value = content
+		}
+
+		if resolve := l.secretResolver(value); resolve != nil && !isQuoted(raw, comment) {
+			secret, err := resolve(value)
+			if err != nil {
+				return nil, &ParseError{File: filename, Line: lineNumber, Err: fmt.Errorf("resolving %s: %w", key, err)}
+			}
+			value = secret
+		}
+
+		if l.MaxValueLen > 0 && len(value) > l.MaxValueLen {
+			return nil, &ParseError{File: filename, Line: lineNumber, Err: fmt.Errorf("value of %s is %d bytes, longer than the limit of %d", key, len(value), l.MaxValueLen)}
+		}
+
+		vars[key] = value
+		entries = append(entries, Entry{
+			Key:     key,
+			Value:   value,
+			Comment: inlineComment(raw, comment),
+			File:    filename,
+			Line:    lineNumber,
+		})
+	}
+
+	return entries, nil
+}
+
+// foldedEnviron maps the lowercased name of every process environment
+// variable to its name, for DetectCaseCollisions. It returns an empty map
+// when the option is off.
+func (l *Loader) foldedEnviron() map[string]string {
+	folded := make(map[string]string)
+	if !l.Strict || !l.DetectCaseCollisions {
+		return folded
+	}
+
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		folded[strings.ToLower(name)] = name
+	}
+	return folded
+}
+
+// secretResolver returns the resolver registered for the longest scheme
+// prefix of value, or nil when none matches.
+func (l *Loader) secretResolver(value string) SecretResolver {
+	var resolve SecretResolver
+	longest := -1
+	for scheme, r := range l.SecretResolvers {
+		if strings.HasPrefix(value, scheme) && len(scheme) > longest {
+			resolve, longest = r, len(scheme)
+		}
+	}
+	return resolve
+}
+
+// directive starts an optional first line that sets parsing options for the
+// rest of the file, such as "#!dotenv v1 strict expand".
+const directive = "#!dotenv"
+
+// withDirective returns a copy of the loader with the options named by the
+// words of a directive line enabled. The version "v1" is accepted and
+// ignored; any other unknown word is an error.
+func (l *Loader) withDirective(words []string) (*Loader, error) {
+	options := *l
+	for _, word := range words {
+		switch word {
+		case "v1":
+		case "strict":
+			options.Strict = true
+		case "expand":
+			options.Expand = true
+		default:
+			return nil, fmt.Errorf("unknown directive option %q", word)
+		}
+	}
+	return &options, nil
+}
+
+// includeDirective starts a line that reads another file at that point, as
+// in "@include base.env".
+const includeDirective = "@include"
+
+// maxIncludeDepth is how deeply @include lines may nest.
+const maxIncludeDepth = 16
+
+// cutInclude returns the path named by an @include line.
+func cutInclude(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, includeDirective)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// include parses the file target, named by an @include line of filename,
+// into vars and returns its entries. A relative target is resolved from the
+// directory of filename. Including a file that is already being read, or
+// nesting deeper than maxIncludeDepth, is an error.
+func (l *Loader) include(filename string, line int, target string, vars map[string]string) ([]Entry, error) {
+	if target == "" {
+		return nil, &ParseError{File: filename, Line: line, Err: errors.New("@include needs a file name")}
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(filename), target)
+	}
+
+	current, _ := filepath.Abs(filename)
+	path, _ := filepath.Abs(target)
+	chain := append(slices.Clone(l.including), current)
+	if slices.Contains(chain, path) {
+		return nil, &ParseError{File: filename, Line: line, Err: fmt.Errorf("include cycle: %s -> %s", strings.Join(chain, " -> "), path)}
+	}
+	if len(chain) > maxIncludeDepth {
+		return nil, &ParseError{File: filename, Line: line, Err: fmt.Errorf("includes nested deeper than %d files", maxIncludeDepth)}
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return nil, &ParseError{File: filename, Line: line, Err: err}
+	}
+	if l.included != nil && !slices.Contains(*l.included, target) {
+		*l.included = append(*l.included, target)
+	}
+
+	child := *l
+	child.including = chain
+	return child.parseFile(target, string(content), vars)
+}
+
+// readFileRef returns the content of the file referenced by an @PATH value,
+// without one trailing newline.
+func readFileRef(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading file reference: %w", err)
+	}
+
+	return trimNewline(string(content)), nil
+}
+
+// trimNewline removes at most one trailing "\n" or "\r\n" from value. Other
+// whitespace, including further newlines and leading spaces, may be part of
+// a secret and is kept, so it does not use strings.TrimSpace.
+func trimNewline(value string) string {
+	if !strings.HasSuffix(value, "\n") {
+		return value
+	}
+
+	value = value[:len(value)-1]
+	return strings.TrimSuffix(value, "\r")
+}
+
+// Effective returns the variables the application would see after calling
+// Load with filenames: the process environment, plus every file variable
+// that the environment does not already define. Nothing is modified, which
+// makes it suitable for a --dump-env style debug command. Files that cannot
+// be read or parsed are ignored one by one, so the others still apply.
+func Effective(filenames ...string) map[string]string {
+	effective := make(map[string]string)
+
+	loader := Loader{Filenames: filenames}
+	for _, filename := range loader.filenames() {
+		file := Loader{Filenames: []string{filename}}
+		vars, err := file.Parse()
+		if err != nil {
+			continue
+		}
+
+		for key, value := range vars {
+			effective[key] = value
+		}
+	}
+
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		effective[key] = value
+	}
+
+	return effective
+}
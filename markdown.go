@@ -0,0 +1,58 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// MarshalMarkdown documents the keys declared by dest as a Markdown table
+// with the columns Key, Type, Default, Required and Description, one row
+// per key in field order. The description comes from the 'comment' tag.
+// Map fields tagged with 'envPrefix' are listed as PREFIX*. It is meant to
+// be run from go generate to keep configuration docs up to date.
+func MarshalMarkdown(dest interface{}) ([]byte, error) {
+	rv, err := structValue(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("| Key | Type | Default | Required | Description |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	seen := make(map[string]bool)
+	for _, f := range cachedFields(rv.Type()) {
+		key := f.key
+		if f.prefix != "" {
+			key = f.prefix + "*"
+		}
+
+		// Fields tagged with 'flag' may share a key, which is listed once.
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		defaultValue := ""
+		if f.defaultValue != "" {
+			defaultValue = "`" + markdownCell(f.defaultValue) + "`"
+		}
+
+		required := "no"
+		if f.required {
+			required = "yes"
+		}
+
+		fmt.Fprintf(&buf, "| `%s` | `%s` | %s | %s | %s |\n",
+			key, rv.Type().FieldByIndex(f.index).Type, defaultValue, required, markdownCell(f.comment))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// markdownCell escapes value for use inside a Markdown table cell.
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", " ")
+}
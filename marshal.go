@@ -0,0 +1,465 @@
+package dotenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MarshalOptions configures how structs are written in .env format.
+// The zero value matches Marshal.
+type MarshalOptions struct {
+	// QuoteStrings double-quotes the value of every string field, escaping
+	// backslashes and double quotes, even when the value needs no quoting.
+	// Numeric and boolean fields are still written bare.
+	QuoteStrings bool
+
+	// KeyFunc, when non-nil, maps each key before it is written. It receives
+	// the key from the 'env' tag, or the full name for an 'envPrefix' map
+	// entry, and the name of the struct field it came from, so it can add a
+	// namespace or rename reserved keys.
+	KeyFunc func(tagKey, fieldName string) string
+
+	// OmitTrailingNewline drops the newline after the last line, for tools
+	// that expect none. By default the output ends with exactly one
+	// newline. Empty output stays empty either way.
+	OmitTrailingNewline bool
+}
+
+// Marshal converts dest into a .env formatted byte slice. A struct without
+// tagged fields gives an empty, non-nil slice.
+func (o MarshalOptions) Marshal(dest interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := o.MarshalTo(&buf, dest); err != nil {
+		return nil, err
+	}
+
+	if buf.Len() == 0 {
+		return []byte{}, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalTo writes the .env form of dest to w.
+func (o MarshalOptions) MarshalTo(w io.Writer, dest interface{}) error {
+	pairs, err := marshalPairs(dest)
+	if err != nil {
+		return err
+	}
+
+	if o.KeyFunc != nil {
+		for i := range pairs {
+			pairs[i].key = o.KeyFunc(pairs[i].key, pairs[i].name)
+		}
+	}
+
+	return o.writePairs(w, pairs)
+}
+
+// pair is a key and its unquoted value, as produced by marshalPairs.
+type pair struct {
+	key   string
+	value string
+
+	// name is the struct field the pair came from. Pairs built from a
+	// file, rather than a struct, leave it empty.
+	name string
+
+	// section is the section of the nested struct the field belongs to,
+	// written as a "# [Section]" header before the pair.
+	section string
+
+	// text reports whether the value came from a string field.
+	text bool
+}
+
+// structValue returns the struct held by dest, dereferencing a pointer. Its
+// errors tell a nil pointer, a pointer to a non-struct and any other
+// non-struct value apart.
+func structValue(dest interface{}) (reflect.Value, error) {
+	if dest == nil {
+		return reflect.Value{}, errors.New("dest must be a struct or a pointer to a struct, got nil")
+	}
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("dest is a nil %s", rv.Type())
+		}
+
+		rv = rv.Elem()
+		if rv.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("dest must point to a struct, got %s", reflect.TypeOf(dest))
+		}
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("dest must be a struct or a pointer to a struct, got %s", rv.Type())
+	}
+
+	return rv, nil
+}
+
+// marshalPairs returns the key-value pairs of the tagged fields of dest in
+// field order, applying defaults and required checks.
+func marshalPairs(dest interface{}) ([]pair, error) {
+	rv, err := structValue(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []pair
+	// flags maps the key shared by 'flag' fields to the index of its pair,
+	// so every set flag is written into a single list.
+	flags := make(map[string]int)
+	for _, f := range cachedFields(rv.Type()) {
+		if f.flag != "" {
+			i, ok := flags[f.key]
+			if !ok {
+				i = len(pairs)
+				flags[f.key] = i
+				pairs = append(pairs, pair{key: f.key, name: f.name, section: f.section})
+			}
+
+			pairs[i].value = flagValue(pairs[i].value, rv, f)
+			continue
+		}
+
+		if f.prefix != "" {
+			entries, err := mapPairs(rv.FieldByIndex(f.index), f)
+			if err != nil {
+				return nil, err
+			}
+			for i := range entries {
+				entries[i].name = f.name
+				entries[i].section = f.section
+			}
+			pairs = append(pairs, entries...)
+			continue
+		}
+
+		p, err := fieldPair(rv, f)
+		if err != nil {
+			return nil, err
+		}
+		p.name = f.name
+		p.section = f.section
+		pairs = append(pairs, p)
+	}
+
+	return pairs, nil
+}
+
+// flagValue adds the token of the 'flag' field f to list when the field is
+// true in rv.
+func flagValue(list string, rv reflect.Value, f field) string {
+	if fv := rv.FieldByIndex(f.index); fv.Kind() == reflect.Bool && fv.Bool() {
+		return addFlag(list, f.flag)
+	}
+	return list
+}
+
+// fieldPair returns the key-value pair of a single field of rv.
+func fieldPair(rv reflect.Value, f field) (pair, error) {
+	fv := rv.FieldByIndex(f.index)
+	if f.pointer {
+		if fv.IsNil() {
+			if f.required {
+				return pair{}, fmt.Errorf("env %s for field %s is required", f.key, f.name)
+			}
+			return pair{key: f.key, text: fv.Type().Elem().Kind() == reflect.String}, nil
+		}
+		fv = fv.Elem()
+	}
+
+	var value string
+	if f.split != "" {
+		value = joinSplitField(fv, f.split)
+	} else {
+		formatted, err := formatValue(fv, f.format)
+		if err != nil {
+			return pair{}, fmt.Errorf("error marshaling field %s: %w", f.name, err)
+		}
+		value = formatted
+	}
+
+	if value == "" {
+		if f.defaultValue != "" {
+			value = f.defaultValue
+		} else if f.required {
+			return pair{}, fmt.Errorf("env %s for field %s is required", f.key, f.name)
+		}
+	}
+
+	return pair{key: f.key, value: value, text: fv.Kind() == reflect.String}, nil
+}
+
+// mapPairs returns one pair per entry of a map field tagged with
+// 'envPrefix', named by the prefix followed by the map key and sorted by
+// name so the output is deterministic.
+func mapPairs(fv reflect.Value, f field) ([]pair, error) {
+	pairs := make([]pair, 0, fv.Len())
+
+	iter := fv.MapRange()
+	for iter.Next() {
+		key := f.prefix + fmt.Sprintf("%v", iter.Key().Interface())
+		value, err := formatValue(iter.Value(), f.format)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling %s: %w", key, err)
+		}
+
+		pairs = append(pairs, pair{
+			key:   key,
+			value: value,
+			text:  iter.Value().Kind() == reflect.String,
+		})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].key < pairs[j].key
+	})
+
+	return pairs, nil
+}
+
+// writePairs writes pairs to w as KEY=VALUE lines, double-quoting and
+// escaping values that contain spaces, newlines, surrounding whitespace or
+// a double quote, start with a quote, or end in a backslash, so every value
+// is read back verbatim. Pairs from a nested struct are grouped under a
+// "# [Section]" header.
+func (o MarshalOptions) writePairs(w io.Writer, pairs []pair) error {
+	var builder strings.Builder
+	section := ""
+	for i, p := range pairs {
+		// Each run of pairs from one nested struct is preceded by a
+		// header, and a blank line separates it from what came before.
+		if p.section != section {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+			if p.section != "" {
+				fmt.Fprintf(&builder, "# [%s]\n", p.section)
+			}
+			section = p.section
+		}
+
+		// A value ending in a backslash would continue onto the next line
+		// when read back, a newline would end the line early, and a bare
+		// quote could open a value spanning the lines that follow.
+		value := p.value
+		if (o.QuoteStrings && p.text) ||
+			strings.ContainsAny(value, " \"\n\r") ||
+			strings.HasPrefix(value, "'") ||
+			strings.HasSuffix(value, `\`) ||
+			strings.TrimSpace(value) != value {
+			value = quoteValue(value)
+		}
+
+		builder.WriteString(fmt.Sprintf("%s=%s\n", p.key, value))
+	}
+
+	output := builder.String()
+	if o.OmitTrailingNewline {
+		output = strings.TrimSuffix(output, "\n")
+	}
+
+	_, err := io.WriteString(w, output)
+	return err
+}
+
+// quoteValue wraps value in double quotes, escaping backslashes and double
+// quotes with a backslash and writing newlines and carriage returns as \n
+// and \r, so the value stays on one line.
+func quoteValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, "\r", `\r`)
+	return `"` + value + `"`
+}
+
+// MarshalFields converts only the fields of dest whose 'env' key is listed
+// in keys, in the order given. It returns an error naming any key that dest
+// does not declare. Fields that are not selected are not checked, so an
+// empty required field only fails when it is requested.
+func MarshalFields(dest interface{}, keys ...string) ([]byte, error) {
+	rv, err := structValue(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]field)
+	// flags collects the 'flag' fields sharing each key, which are written
+	// as one list like Marshal does.
+	flags := make(map[string][]field)
+	for _, f := range cachedFields(rv.Type()) {
+		switch {
+		case f.flag != "":
+			flags[f.key] = append(flags[f.key], f)
+		case f.key != "":
+			byKey[f.key] = f
+		}
+	}
+
+	pairs := make([]pair, 0, len(keys))
+	for _, key := range keys {
+		if group, ok := flags[key]; ok {
+			p := pair{key: key}
+			for _, f := range group {
+				p.value = flagValue(p.value, rv, f)
+			}
+			pairs = append(pairs, p)
+			continue
+		}
+
+		f, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("env %s is not declared by %s", key, rv.Type())
+		}
+
+		p, err := fieldPair(rv, f)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+
+	var buf bytes.Buffer
+	if err := (MarshalOptions{}).writePairs(&buf, pairs); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalDelta converts only the variables of current whose values differ
+// from those of baseline, producing a minimal patch. Both must be structs,
+// or pointers to structs, of the same type. Values are compared in their
+// .env form, after defaults, so a map entry present only in current is
+// included while one removed from it is not reported.
+func MarshalDelta(current, baseline interface{}) ([]byte, error) {
+	cv, err := structValue(current)
+	if err != nil {
+		return nil, err
+	}
+
+	bv, err := structValue(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+
+	if cv.Type() != bv.Type() {
+		return nil, fmt.Errorf("baseline must be a %s, got %s", cv.Type(), bv.Type())
+	}
+
+	currentPairs, err := marshalPairs(current)
+	if err != nil {
+		return nil, err
+	}
+
+	baselinePairs, err := marshalPairs(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+
+	previous := make(map[string]string, len(baselinePairs))
+	for _, p := range baselinePairs {
+		previous[p.key] = p.value
+	}
+
+	var changed []pair
+	for _, p := range currentPairs {
+		if value, ok := previous[p.key]; !ok || value != p.value {
+			changed = append(changed, p)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := (MarshalOptions{}).writePairs(&buf, changed); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalEntries writes entries, as returned by Loader.ParseWithSource, as a
+// single merged .env document. Only the last assignment of each key is
+// kept, and the variables are grouped by the file they came from, each
+// group headed by a "# from FILE" comment. Groups follow the order of the
+// files and variables keep the order they were read in.
+func MarshalEntries(entries []Entry) ([]byte, error) {
+	last := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		last[entry.Key] = i
+	}
+
+	var files []string
+	groups := make(map[string][]pair)
+	for i, entry := range entries {
+		if last[entry.Key] != i {
+			continue
+		}
+
+		if _, ok := groups[entry.File]; !ok {
+			files = append(files, entry.File)
+		}
+		groups[entry.File] = append(groups[entry.File], pair{key: entry.Key, value: entry.Value, text: true})
+	}
+
+	var buf bytes.Buffer
+	for i, file := range files {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		fmt.Fprintf(&buf, "# from %s\n", file)
+		if err := (MarshalOptions{}).writePairs(&buf, groups[file]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalMapOrdered writes m in .env format with its keys in the order
+// given, so a file read into a map can be written back in its original
+// order. Keys listed in order but missing from m are skipped, and a key
+// listed twice is written once. Keys of m not listed in order follow,
+// sorted. An empty key is an error, since it could not be read back.
+func MarshalMapOrdered(m map[string]string, order []string) ([]byte, error) {
+	if _, ok := m[""]; ok {
+		return nil, errors.New("cannot marshal an empty key")
+	}
+
+	pairs := make([]pair, 0, len(m))
+	written := make(map[string]bool, len(m))
+	for _, key := range order {
+		if value, ok := m[key]; ok && !written[key] {
+			written[key] = true
+			pairs = append(pairs, pair{key: key, value: value, text: true})
+		}
+	}
+
+	rest := make([]string, 0, len(m)-len(pairs))
+	for key := range m {
+		if !written[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	for _, key := range rest {
+		pairs = append(pairs, pair{key: key, value: m[key], text: true})
+	}
+
+	var buf bytes.Buffer
+	if err := (MarshalOptions{}).writePairs(&buf, pairs); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package dotenv
+
+import "sort"
+
+// Override describes a key that more than one file defined.
+type Override struct {
+	Key string
+
+	// File is the file whose value was used, which is the last file in load
+	// order that defines the key.
+	File string
+
+	// Overridden lists the earlier files whose values were replaced, in
+	// load order.
+	Overridden []string
+}
+
+// Report describes how layered files were combined by LoadWithReport.
+type Report struct {
+	// Overrides lists the keys defined by more than one file, sorted by key.
+	// A key repeated within a single file is not reported.
+	Overrides []Override
+}
+
+// LoadWithReport loads filenames like Load and also reports every key that
+// a later file overrode, with the file that won. It is meant for debugging
+// layered configuration such as .env and .env.local.
+func LoadWithReport(filenames ...string) (Report, error) {
+	loader := Loader{Filenames: filenames}
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		return Report{}, err
+	}
+
+	vars := make(map[string]string, len(entries))
+	files := make(map[string][]string)
+	for _, entry := range entries {
+		vars[entry.Key] = entry.Value
+
+		seen := files[entry.Key]
+		if len(seen) == 0 || seen[len(seen)-1] != entry.File {
+			files[entry.Key] = append(seen, entry.File)
+		}
+	}
+
+	var report Report
+	for key, seen := range files {
+		if len(seen) < 2 {
+			continue
+		}
+
+		report.Overrides = append(report.Overrides, Override{
+			Key:        key,
+			File:       seen[len(seen)-1],
+			Overridden: seen[:len(seen)-1],
+		})
+	}
+
+	sort.Slice(report.Overrides, func(i, j int) bool {
+		return report.Overrides[i].Key < report.Overrides[j].Key
+	})
+
+	if err := loader.applyVars(vars, nil, false); err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidateSchema checks that the keys defined in filename match exactly the
+// keys declared by the 'env' tags of dest. It is intended for CI checks that
+// keep files such as .env.example in sync with the config struct.
+//
+// The returned error lists the keys missing from the file and the keys the
+// file defines that the struct does not declare.
+func ValidateSchema(dest interface{}, filename string) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return errors.New("dest must be a struct or a pointer to a struct")
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	vars := make(map[string]string)
+	loader := Loader{}
+	if _, err := loader.parse(filename, string(content), vars); err != nil {
+		return err
+	}
+
+	declared := make(map[string]bool)
+	var missing []string
+	for _, key := range Keys(dest) {
+		declared[key] = true
+		if _, ok := vars[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	var extra []string
+	for key := range vars {
+		if !declared[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing keys: "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "extra keys: "+strings.Join(extra, ", "))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s does not match schema: %s", filename, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Keys returns the environment variable names declared by the 'env' tags of
+// dest, in field order. dest may be a struct or a pointer to a struct, and
+// only its type is inspected. Map fields tagged with 'envPrefix' are not
+// included, since their keys are only known once the environment is read.
+// Keys returns nil when dest is not a struct.
+func Keys(dest interface{}) []string {
+	t := reflect.TypeOf(dest)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, f := range cachedFields(t) {
+		// Fields tagged with 'flag' may share a key, which is listed once.
+		if f.key != "" && !seen[f.key] {
+			seen[f.key] = true
+			keys = append(keys, f.key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,64 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Shell selects the syntax MarshalShell writes.
+type Shell string
+
+const (
+	// Bash writes POSIX export statements, also understood by sh and zsh:
+	// export KEY='value'.
+	Bash Shell = "bash"
+
+	// Fish writes fish set statements: set -x KEY 'value'.
+	Fish Shell = "fish"
+
+	// PowerShell writes environment assignments: $env:KEY = 'value'.
+	PowerShell Shell = "powershell"
+)
+
+// MarshalShell converts dest into statements that export its variables in
+// the given shell, for use with eval "$(myapp env)". Values are always
+// single-quoted, so the shell performs no expansion on them. Keys cannot be
+// quoted, so a key that is not a valid POSIX variable name, such as a map
+// key taken from the environment, is an error.
+func MarshalShell(dest interface{}, shell Shell) ([]byte, error) {
+	var format func(key, value string) string
+	switch shell {
+	case Bash:
+		format = func(key, value string) string {
+			return fmt.Sprintf("export %s='%s'\n", key, strings.ReplaceAll(value, "'", `'\''`))
+		}
+	case Fish:
+		format = func(key, value string) string {
+			value = strings.ReplaceAll(value, `\`, `\\`)
+			value = strings.ReplaceAll(value, "'", `\'`)
+			return fmt.Sprintf("set -x %s '%s'\n", key, value)
+		}
+	case PowerShell:
+		format = func(key, value string) string {
+			return fmt.Sprintf("$env:%s = '%s'\n", key, strings.ReplaceAll(value, "'", "''"))
+		}
+	default:
+		return nil, fmt.Errorf("unknown shell %q", shell)
+	}
+
+	pairs, err := marshalPairs(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, p := range pairs {
+		if !isName(p.key) {
+			return nil, fmt.Errorf("cannot export %q: not a valid variable name", p.key)
+		}
+		buf.WriteString(format(p.key, p.value))
+	}
+
+	return buf.Bytes(), nil
+}
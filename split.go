@@ -0,0 +1,52 @@
+package dotenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// setSplitField splits value on sep and assigns each part, in order, to the
+// exported fields of the struct field.
+func setSplitField(field reflect.Value, value, sep string) error {
+	if field.Kind() != reflect.Struct {
+		return fmt.Errorf("split requires a struct field, got %s", field.Type())
+	}
+
+	targets := exportedFields(field)
+	parts := strings.SplitN(value, sep, len(targets))
+	if len(parts) != len(targets) {
+		return fmt.Errorf("cannot split %q on %q into %d parts", value, sep, len(targets))
+	}
+
+	for i, target := range targets {
+		if err := setField(target, parts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// joinSplitField reverses setSplitField, joining the exported fields of the
+// struct field with sep.
+func joinSplitField(field reflect.Value, sep string) string {
+	var parts []string
+	for _, target := range exportedFields(field) {
+		// formatValue only fails for the json format.
+		value, _ := formatValue(target, "")
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, sep)
+}
+
+// exportedFields returns the exported fields of the struct value v.
+func exportedFields(v reflect.Value) []reflect.Value {
+	var fields []reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			fields = append(fields, v.Field(i))
+		}
+	}
+	return fields
+}
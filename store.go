@@ -0,0 +1,109 @@
+package dotenv
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// store is an in-memory set of variables kept apart from the process
+// environment. Lookup, Get and Unmarshal consult it before os.LookupEnv.
+var store = struct {
+	sync.RWMutex
+	vars map[string]string
+}{vars: make(map[string]string)}
+
+// LoadStore reads filenames, or FilenameVariables when none are given, into
+// the in-memory store instead of the process environment. It is meant for
+// libraries and tests that must not modify global state. Later files
+// override earlier ones, and loaded values override values already stored.
+func LoadStore(filenames ...string) error {
+	loader := Loader{Filenames: filenames}
+	vars, err := loader.Parse()
+	if err != nil {
+		return err
+	}
+
+	store.Lock()
+	defer store.Unlock()
+
+	for key, value := range vars {
+		store.vars[key] = value
+	}
+
+	return nil
+}
+
+// Set stores value under key in the in-memory store.
+func Set(key, value string) {
+	store.Lock()
+	defer store.Unlock()
+
+	store.vars[key] = value
+}
+
+// Lookup returns the value of key from the in-memory store, falling back to
+// the process environment. The boolean reports whether the key was found in
+// either.
+func Lookup(key string) (string, bool) {
+	if value, ok := storeLookup(key); ok {
+		return value, true
+	}
+	return os.LookupEnv(key)
+}
+
+// storeLookup returns the value of key from the in-memory store only.
+func storeLookup(key string) (string, bool) {
+	store.RLock()
+	defer store.RUnlock()
+
+	value, ok := store.vars[key]
+	return value, ok
+}
+
+// Get returns the value of key like Lookup, or "" when it is not set.
+func Get(key string) string {
+	value, _ := Lookup(key)
+	return value
+}
+
+// CollectPrefix returns every variable whose name starts with prefix, keyed
+// by the rest of its name, so a plugin can discover its own settings without
+// declaring a struct. Like Lookup, it reads the in-memory store and the
+// process environment, with the store taking precedence. A variable named
+// exactly prefix is skipped. The result is empty, not nil, when nothing
+// matches.
+func CollectPrefix(prefix string) map[string]string {
+	vars := make(map[string]string)
+	for _, entry := range environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, prefix) && name != prefix {
+			vars[strings.TrimPrefix(name, prefix)] = value
+		}
+	}
+	return vars
+}
+
+// ClearStore removes every value from the in-memory store. The process
+// environment is not affected.
+func ClearStore() {
+	store.Lock()
+	defer store.Unlock()
+
+	store.vars = make(map[string]string)
+}
+
+// environ returns the process environment followed by the in-memory store
+// entries, in the "KEY=VALUE" form of os.Environ.
+func environ() []string {
+	env := os.Environ()
+
+	store.RLock()
+	defer store.RUnlock()
+
+	for key, value := range store.vars {
+		env = append(env, key+"="+value)
+	}
+
+	return env
+}
@@ -0,0 +1,69 @@
+package dotenv_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestParseArgs(t *testing.T) {
+	t.Setenv("TEST_ARGS_USER", "gopher")
+
+	t.Run("plain and quoted", func(t *testing.T) {
+		vars, err := dotenv.ParseArgs([]string{
+			"TEST_ARGS_HOST=localhost",
+			`TEST_ARGS_NAME="two words"`,
+			"TEST_ARGS_RAW='${TEST_ARGS_USER}'",
+			"TEST_ARGS_HOME=/home/${TEST_ARGS_USER}",
+			`TEST_ARGS_URL="http://${TEST_ARGS_HOST}:8080"`,
+			"TEST_ARGS_PORT=8080 # comment",
+			"TEST_ARGS_EMPTY=",
+			"TEST_ARGS_HOST=override",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := map[string]string{
+			"TEST_ARGS_HOST":  "override",
+			"TEST_ARGS_NAME":  "two words",
+			"TEST_ARGS_RAW":   "${TEST_ARGS_USER}",
+			"TEST_ARGS_HOME":  "/home/gopher",
+			"TEST_ARGS_URL":   "http://localhost:8080",
+			"TEST_ARGS_PORT":  "8080",
+			"TEST_ARGS_EMPTY": "",
+		}
+		if !reflect.DeepEqual(vars, expected) {
+			t.Errorf("expected %v, got %v", expected, vars)
+		}
+	})
+
+	t.Run("not an assignment", func(t *testing.T) {
+		_, err := dotenv.ParseArgs([]string{"TEST_ARGS_HOST=localhost", "--verbose"})
+
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) || parseErr.File != "argument 1" {
+			t.Fatalf("expected ParseError for argument 1, got %v", err)
+		}
+	})
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		if _, err := dotenv.ParseArgs([]string{`TEST_ARGS_NAME="open`}); err == nil {
+			t.Fatal("expected error for unterminated quote, got nil")
+		}
+	})
+
+	t.Run("loader without expansion", func(t *testing.T) {
+		loader := dotenv.Loader{}
+		vars, err := loader.ParseArgs([]string{"TEST_ARGS_HOME=/home/${TEST_ARGS_USER}"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_ARGS_HOME"] != "/home/${TEST_ARGS_USER}" {
+			t.Errorf("expected the reference kept, got %q", vars["TEST_ARGS_HOME"])
+		}
+	})
+}
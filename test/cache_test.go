@@ -0,0 +1,117 @@
+package dotenv_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestCache(t *testing.T) {
+	env := writeEnvFile(t, ".env", "TEST_CACHE_HOST=localhost\nTEST_CACHE_PORT=8080\n")
+	local := writeEnvFile(t, ".env.local", "TEST_CACHE_PORT=9090\n")
+	path := filepath.Join(t.TempDir(), "env.cache")
+
+	t.Run("write and hit", func(t *testing.T) {
+		if err := dotenv.CacheTo(path, env, local); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		vars, err := dotenv.LoadCache(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_CACHE_HOST"] != "localhost" || vars["TEST_CACHE_PORT"] != "9090" {
+			t.Errorf("unexpected cached variables: %v", vars)
+		}
+	})
+
+	t.Run("invalidated by a source change", func(t *testing.T) {
+		if err := dotenv.CacheTo(path, env, local); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := os.WriteFile(local, []byte("TEST_CACHE_PORT=7070\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(local, later, later); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := dotenv.LoadCache(path); !errors.Is(err, dotenv.ErrStaleCache) {
+			t.Fatalf("expected ErrStaleCache, got %v", err)
+		}
+
+		if err := dotenv.CacheTo(path, env, local); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		vars, err := dotenv.LoadCache(path)
+		if err != nil {
+			t.Fatalf("unexpected error after rebuild: %v", err)
+		}
+
+		if vars["TEST_CACHE_PORT"] != "7070" {
+			t.Errorf("expected rebuilt value %q, got %q", "7070", vars["TEST_CACHE_PORT"])
+		}
+	})
+
+	t.Run("invalidated by a change to an included file", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{
+			"base.env": "TEST_CACHE_HOST=base\n",
+			".env":     "@include base.env\n",
+		})
+		base := filepath.Join(dir, "base.env")
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, ".env")}}
+		if err := loader.CacheTo(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		vars, err := dotenv.LoadCache(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if vars["TEST_CACHE_HOST"] != "base" {
+			t.Fatalf("expected the included value, got %v", vars)
+		}
+
+		if err := os.WriteFile(base, []byte("TEST_CACHE_HOST=changed\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(base, later, later); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := dotenv.LoadCache(path); !errors.Is(err, dotenv.ErrStaleCache) {
+			t.Fatalf("expected ErrStaleCache, got %v", err)
+		}
+	})
+
+	t.Run("invalidated by a removed source", func(t *testing.T) {
+		removed := writeEnvFile(t, ".env", "TEST_CACHE_HOST=gone\n")
+		if err := dotenv.CacheTo(path, removed); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := os.Remove(removed); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := dotenv.LoadCache(path); !errors.Is(err, dotenv.ErrStaleCache) {
+			t.Fatalf("expected ErrStaleCache, got %v", err)
+		}
+	})
+
+	t.Run("missing cache", func(t *testing.T) {
+		if _, err := dotenv.LoadCache(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected os.ErrNotExist, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package dotenv_test
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestApplyToCmd(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		cfg := ConfigTest{
+			Host:      "child.local",
+			Port:      9000,
+			Debug:     true,
+			RateLimit: 1.5,
+		}
+
+		cmd := exec.Command("sh", "-c", `printf %s "$TEST_HOST:$TEST_PORT"`)
+		if err := dotenv.ApplyToCmd(cmd, &cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(output); got != "child.local:9000" {
+			t.Errorf("expected %q, got %q", "child.local:9000", got)
+		}
+	})
+
+	t.Run("map", func(t *testing.T) {
+		cmd := exec.Command("sh", "-c", `printf %s "$TEST_CHILD_VAR"`)
+		cmd.Env = []string{"PATH=/usr/bin:/bin"}
+		dotenv.ApplyMapToCmd(cmd, map[string]string{"TEST_CHILD_VAR": "from map"})
+
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(output); got != "from map" {
+			t.Errorf("expected %q, got %q", "from map", got)
+		}
+	})
+
+	t.Run("invalid dest returns error", func(t *testing.T) {
+		if err := dotenv.ApplyToCmd(exec.Command("true"), "invalid"); err == nil {
+			t.Fatal("expected error for non-struct, got nil")
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package dotenv_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type AuthConfig struct {
+	Mode   string `env:"TEST_AUTH_MODE" default:"token"`
+	APIKey string `env:"TEST_API_KEY" requiredUnless:"TEST_AUTH_MODE=none"`
+	TLS    bool   `env:"TEST_TLS"`
+	Cert   string `env:"TEST_TLS_CERT" requiredIf:"TEST_TLS=true"`
+}
+
+func TestUnmarshalConditionalRequired(t *testing.T) {
+	t.Run("required unless satisfied by condition", func(t *testing.T) {
+		t.Setenv("TEST_AUTH_MODE", "none")
+		os.Unsetenv("TEST_API_KEY")
+		os.Unsetenv("TEST_TLS")
+
+		var cfg AuthConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("required unless satisfied by value", func(t *testing.T) {
+		os.Unsetenv("TEST_AUTH_MODE")
+		t.Setenv("TEST_API_KEY", "secret")
+		os.Unsetenv("TEST_TLS")
+
+		var cfg AuthConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("required unless violated", func(t *testing.T) {
+		os.Unsetenv("TEST_AUTH_MODE")
+		os.Unsetenv("TEST_API_KEY")
+		os.Unsetenv("TEST_TLS")
+
+		var cfg AuthConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected error for missing API key, got nil")
+		}
+	})
+
+	t.Run("required if violated", func(t *testing.T) {
+		t.Setenv("TEST_AUTH_MODE", "none")
+		t.Setenv("TEST_TLS", "true")
+		os.Unsetenv("TEST_TLS_CERT")
+
+		var cfg AuthConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected error for missing certificate, got nil")
+		}
+	})
+
+	t.Run("required if satisfied", func(t *testing.T) {
+		t.Setenv("TEST_AUTH_MODE", "none")
+		t.Setenv("TEST_TLS", "true")
+		t.Setenv("TEST_TLS_CERT", "/etc/cert.pem")
+
+		var cfg AuthConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestUnmarshalDefaultIf(t *testing.T) {
+	type ServerConfig struct {
+		Scheme string `env:"TEST_SCHEME" default:"http"`
+		Port   int    `env:"TEST_SERVER_PORT" defaultIf:"TEST_SCHEME=https:443" default:"80"`
+	}
+
+	tests := []struct {
+		name   string
+		scheme string
+		port   string
+		want   int
+	}{
+		{"https derives 443", "https", "", 443},
+		{"http falls back to default", "http", "", 80},
+		{"scheme default falls back to default", "", "", 80},
+		{"explicit port wins", "https", "8443", 8443},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_SCHEME", tt.scheme)
+			t.Setenv("TEST_SERVER_PORT", tt.port)
+
+			var cfg ServerConfig
+			if err := dotenv.Unmarshal(&cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Port != tt.want {
+				t.Errorf("expected port %d, got %d", tt.want, cfg.Port)
+			}
+		})
+	}
+}
+
+type CredentialsConfig struct {
+	APIKey   string `env:"TEST_GROUP_API_KEY" requiredGroup:"auth"`
+	Username string `env:"TEST_GROUP_USERNAME" requiredGroup:"auth"`
+	Region   string `env:"TEST_GROUP_REGION" requiredGroup:"location"`
+	Zone     string `env:"TEST_GROUP_ZONE" requiredGroup:"location" default:"a"`
+}
+
+func TestUnmarshalRequiredGroup(t *testing.T) {
+	t.Run("satisfied group", func(t *testing.T) {
+		os.Unsetenv("TEST_GROUP_API_KEY")
+		t.Setenv("TEST_GROUP_USERNAME", "gopher")
+		os.Unsetenv("TEST_GROUP_REGION")
+		os.Unsetenv("TEST_GROUP_ZONE")
+
+		var cfg CredentialsConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Username != "gopher" {
+			t.Errorf("expected Username to be set, got %q", cfg.Username)
+		}
+	})
+
+	t.Run("entirely missing group", func(t *testing.T) {
+		os.Unsetenv("TEST_GROUP_API_KEY")
+		t.Setenv("TEST_GROUP_USERNAME", "")
+
+		var cfg CredentialsConfig
+		err := dotenv.Unmarshal(&cfg)
+		if err == nil {
+			t.Fatal("expected error for an empty group, got nil")
+		}
+
+		expected := "error at least one of TEST_GROUP_API_KEY, TEST_GROUP_USERNAME must be set (group auth)"
+		if err.Error() != expected {
+			t.Errorf("expected %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package dotenv_test
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+// fakeDecoder reads KEY: value lines.
+type fakeDecoder struct{}
+
+func (fakeDecoder) Decode(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), ":"); ok {
+			vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return vars, scanner.Err()
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	dotenv.RegisterDecoder(".fake", fakeDecoder{})
+
+	fake := writeEnvFile(t, "config.fake", "TEST_FAKE_HOST: db.internal\nTEST_FAKE_PORT: 5432\n")
+	env := writeEnvFile(t, ".env.local", "TEST_FAKE_PORT=6543\n")
+
+	t.Run("dispatches on extension", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{fake, env}}
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_FAKE_HOST"] != "db.internal" {
+			t.Errorf("TEST_FAKE_HOST: expected %q, got %q", "db.internal", vars["TEST_FAKE_HOST"])
+		}
+
+		if vars["TEST_FAKE_PORT"] != "6543" {
+			t.Errorf("TEST_FAKE_PORT: expected the .env.local override, got %q", vars["TEST_FAKE_PORT"])
+		}
+	})
+
+	t.Run("load sets variables", func(t *testing.T) {
+		os.Unsetenv("TEST_FAKE_HOST")
+		t.Cleanup(func() { os.Unsetenv("TEST_FAKE_HOST") })
+
+		if err := dotenv.Load(fake); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := os.Getenv("TEST_FAKE_HOST"); got != "db.internal" {
+			t.Errorf("expected %q, got %q", "db.internal", got)
+		}
+	})
+
+	t.Run("built-in decoder", func(t *testing.T) {
+		vars, err := dotenv.EnvDecoder{}.Decode(strings.NewReader("TEST_FAKE_HOST=\"quoted\" # note\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_FAKE_HOST"] != "quoted" {
+			t.Errorf("expected %q, got %q", "quoted", vars["TEST_FAKE_HOST"])
+		}
+	})
+}
@@ -2,6 +2,7 @@ package dotenv_test
 
 import (
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -149,6 +150,27 @@ func TestUnmarshal(t *testing.T) {
 		}
 	})
 
+	t.Run("uses envDefault when env is missing", func(t *testing.T) {
+		os.Unsetenv("TEST_ENV_DEFAULT")
+
+		var cfg struct {
+			Region string `env:"TEST_ENV_DEFAULT" envDefault:"us-east-1"`
+			Zone   string `env:"TEST_ENV_DEFAULT" default:"a" envDefault:"b"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Region != "us-east-1" {
+			t.Errorf("Region: expected %q, got %q", "us-east-1", cfg.Region)
+		}
+
+		if cfg.Zone != "a" {
+			t.Errorf("Zone: expected default tag to win, got %q", cfg.Zone)
+		}
+	})
+
 	t.Run("required without env and default returns error", func(t *testing.T) {
 		os.Unsetenv("TEST_HOST")
 		os.Unsetenv("TEST_PORT")
@@ -218,6 +240,37 @@ func TestUnmarshal(t *testing.T) {
 			t.Fatal("expected int parse error, got nil")
 		}
 	})
+
+	t.Run("int parse error names value, type and field", func(t *testing.T) {
+		t.Setenv("TEST_TIMEOUT", "30s")
+
+		var cfg struct {
+			Timeout int `env:"TEST_TIMEOUT"`
+		}
+
+		err := dotenv.Unmarshal(&cfg)
+		if err == nil {
+			t.Fatal("expected int parse error, got nil")
+		}
+
+		for _, expected := range []string{`cannot parse "30s" as int`, "Timeout"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error to contain %q, got %q", expected, err)
+			}
+		}
+	})
+
+	t.Run("int overflow returns error", func(t *testing.T) {
+		t.Setenv("TEST_SMALL", "300")
+
+		var cfg struct {
+			Small int8 `env:"TEST_SMALL"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected overflow error, got nil")
+		}
+	})
 }
 
 func TestMarshal(t *testing.T) {
@@ -305,6 +358,86 @@ func TestMarshal(t *testing.T) {
 		}
 	})
 
+	t.Run("quotes values with surrounding whitespace", func(t *testing.T) {
+		cfg := struct {
+			Name string `env:"TEST_NAME"`
+		}{
+			Name: "\tpadded\t",
+		}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "TEST_NAME=\"\tpadded\t\""
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %q, got:\n%s", expected, string(data))
+		}
+	})
+
+	t.Run("quotes values ending in a backslash", func(t *testing.T) {
+		cfg := struct {
+			Path  string `env:"TEST_PATH"`
+			Other string `env:"TEST_OTHER"`
+		}{
+			Path:  `C:\dir\`,
+			Other: "q",
+		}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "TEST_PATH=\"C:\\\\dir\\\\\"\nTEST_OTHER=q\n"
+		if string(data) != expected {
+			t.Errorf("expected %q, got %q", expected, string(data))
+		}
+
+		loader := dotenv.Loader{}
+		vars, err := loader.ParseReaders(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_PATH"] != cfg.Path || vars["TEST_OTHER"] != cfg.Other {
+			t.Errorf("expected the values to read back unchanged, got %q", vars)
+		}
+	})
+
+	t.Run("quotes values with quotes", func(t *testing.T) {
+		cfg := struct {
+			Open   string `env:"TEST_OPEN"`
+			Close  string `env:"TEST_CLOSE"`
+			Single string `env:"TEST_SINGLE"`
+		}{
+			Open:   `"abc`,
+			Close:  `x"`,
+			Single: `'quoted'`,
+		}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := `TEST_OPEN="\"abc"` + "\n" + `TEST_CLOSE="x\""` + "\n" + `TEST_SINGLE="'quoted'"` + "\n"
+		if string(data) != expected {
+			t.Errorf("expected %q, got %q", expected, string(data))
+		}
+
+		loader := dotenv.Loader{}
+		vars, err := loader.ParseReaders(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_OPEN"] != cfg.Open || vars["TEST_CLOSE"] != cfg.Close || vars["TEST_SINGLE"] != cfg.Single {
+			t.Errorf("expected the values to read back unchanged, got %q", vars)
+		}
+	})
+
 	t.Run("required empty string without default returns error", func(t *testing.T) {
 		cfg := struct {
 			Token string `env:"TEST_TOKEN" required:"true"`
@@ -315,17 +448,634 @@ func TestMarshal(t *testing.T) {
 		}
 	})
 
-	t.Run("nil pointer returns error", func(t *testing.T) {
-		var cfg *ConfigTest
+	t.Run("invalid input returns precise error", func(t *testing.T) {
+		name := "invalid"
 
-		if _, err := dotenv.Marshal(cfg); err == nil {
-			t.Fatal("expected error for nil pointer, got nil")
+		tests := map[string]struct {
+			dest     interface{}
+			expected string
+		}{
+			"nil pointer":           {(*ConfigTest)(nil), "dest is a nil *dotenv_test.ConfigTest"},
+			"pointer to non-struct": {&name, "dest must point to a struct, got *string"},
+			"non-struct value":      {"invalid", "dest must be a struct or a pointer to a struct, got string"},
+			"map":                   {map[string]string{}, "got map[string]string"},
+			"untyped nil":           {nil, "got nil"},
+		}
+
+		for label, tt := range tests {
+			t.Run(label, func(t *testing.T) {
+				_, err := dotenv.Marshal(tt.dest)
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				if !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("expected error containing %q, got %v", tt.expected, err)
+				}
+			})
+		}
+	})
+}
+
+func TestMarshalSlice(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		configs := []ConfigTest{
+			{Host: "tenant-a.local", Port: 8001, Debug: true, RateLimit: 1},
+			{Host: "tenant-b.local", Port: 8002, Debug: false, RateLimit: 2.5},
+		}
+
+		var buf strings.Builder
+		if err := dotenv.MarshalSlice(&buf, configs, []byte("---\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "TEST_HOST=tenant-a.local\nTEST_PORT=8001\nTEST_DEBUG=true\nTEST_RATE=1\n" +
+			"---\n" +
+			"TEST_HOST=tenant-b.local\nTEST_PORT=8002\nTEST_DEBUG=false\nTEST_RATE=2.5\n"
+
+		if got := buf.String(); got != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("non slice returns error", func(t *testing.T) {
+		var buf strings.Builder
+		if err := dotenv.MarshalSlice(&buf, ConfigTest{}, nil); err == nil {
+			t.Fatal("expected error for non-slice, got nil")
+		}
+	})
+
+	t.Run("invalid element returns error", func(t *testing.T) {
+		var buf strings.Builder
+		if err := dotenv.MarshalSlice(&buf, []string{"invalid"}, nil); err == nil {
+			t.Fatal("expected error for non-struct element, got nil")
+		}
+	})
+}
+
+func TestMarshalFields(t *testing.T) {
+	cfg := ConfigTest{
+		Host:      "api.prod.com",
+		Port:      9000,
+		Debug:     true,
+		RateLimit: 50.5,
+	}
+
+	t.Run("selects fields in order", func(t *testing.T) {
+		data, err := dotenv.MarshalFields(&cfg, "TEST_RATE", "TEST_HOST")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := "TEST_RATE=50.5\nTEST_HOST=api.prod.com\n"
+		if got := string(data); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("unknown key returns error", func(t *testing.T) {
+		_, err := dotenv.MarshalFields(cfg, "TEST_HOST", "TEST_UNKNOWN")
+		if err == nil {
+			t.Fatal("expected error for unknown key, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "TEST_UNKNOWN") {
+			t.Errorf("expected error to name TEST_UNKNOWN, got %q", err)
+		}
+	})
+}
+
+func TestUnmarshalEnviron(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		os.Unsetenv("TEST_HOST")
+
+		environ := []string{
+			"TEST_HOST=localhost",
+			"TEST_PORT=8080",
+			"TEST_DEBUG=true",
+			"TEST_RATE=1.5",
+			"TEST_UNRELATED=a=b",
+		}
+
+		var cfg ConfigTest
+		if err := dotenv.UnmarshalEnviron(environ, &cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Host != "localhost" || cfg.Port != 8080 || !cfg.Debug || cfg.RateLimit != 1.5 {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+	})
+
+	t.Run("ignores process environment", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "from-process")
+
+		var cfg ConfigTest
+		if err := dotenv.UnmarshalEnviron([]string{"TEST_PORT=8080"}, &cfg); err == nil {
+			t.Fatal("expected required error, got nil")
+		}
+	})
+}
+
+func TestUnmarshalAliases(t *testing.T) {
+	type AliasConfig struct {
+		URL string `env:"TEST_DATABASE_URL|TEST_DB_URL|TEST_POSTGRES_URL" required:"true"`
+	}
+
+	t.Run("uses first alias that is set", func(t *testing.T) {
+		os.Unsetenv("TEST_DATABASE_URL")
+		t.Setenv("TEST_DB_URL", "postgres://second")
+		t.Setenv("TEST_POSTGRES_URL", "postgres://third")
+
+		var cfg AliasConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.URL != "postgres://second" {
+			t.Errorf("URL: expected %q, got %q", "postgres://second", cfg.URL)
+		}
+	})
+
+	t.Run("primary name wins", func(t *testing.T) {
+		t.Setenv("TEST_DATABASE_URL", "postgres://primary")
+		t.Setenv("TEST_DB_URL", "postgres://second")
+
+		var cfg AliasConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.URL != "postgres://primary" {
+			t.Errorf("URL: expected %q, got %q", "postgres://primary", cfg.URL)
+		}
+	})
+
+	t.Run("falls through empty aliases to default", func(t *testing.T) {
+		type DefaultAliasConfig struct {
+			URL string `env:"TEST_DATABASE_URL|TEST_DB_URL" default:"postgres://localhost" required:"true"`
+		}
+
+		t.Setenv("TEST_DATABASE_URL", "")
+		os.Unsetenv("TEST_DB_URL")
+
+		var cfg DefaultAliasConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.URL != "postgres://localhost" {
+			t.Errorf("URL: expected default, got %q", cfg.URL)
+		}
+	})
+
+	t.Run("required after every alias is empty", func(t *testing.T) {
+		os.Unsetenv("TEST_DATABASE_URL")
+		t.Setenv("TEST_DB_URL", "")
+		os.Unsetenv("TEST_POSTGRES_URL")
+
+		var cfg AliasConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected required error, got nil")
+		}
+	})
+
+	t.Run("marshal writes primary name", func(t *testing.T) {
+		data, err := dotenv.Marshal(AliasConfig{URL: "postgres://primary"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(data); got != "TEST_DATABASE_URL=postgres://primary\n" {
+			t.Errorf("unexpected output: %q", got)
+		}
+	})
+}
+
+func TestMarshalQuoteStrings(t *testing.T) {
+	cfg := struct {
+		Name    string `env:"TEST_NAME"`
+		Quote   string `env:"TEST_QUOTE"`
+		Port    int    `env:"TEST_PORT"`
+		Enabled bool   `env:"TEST_ENABLED"`
+	}{
+		Name:    "gopher",
+		Quote:   `say "hi"`,
+		Port:    8080,
+		Enabled: true,
+	}
+
+	data, err := dotenv.MarshalOptions{QuoteStrings: true}.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "TEST_NAME=\"gopher\"\nTEST_QUOTE=\"say \\\"hi\\\"\"\nTEST_PORT=8080\nTEST_ENABLED=true\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestMarshalKeyFunc(t *testing.T) {
+	cfg := struct {
+		Host   string         `env:"host"`
+		Port   int            `env:"port"`
+		Limits map[string]int `envPrefix:"limit_"`
+	}{
+		Host:   "localhost",
+		Port:   8080,
+		Limits: map[string]int{"us": 100},
+	}
+
+	var names []string
+	opts := dotenv.MarshalOptions{
+		KeyFunc: func(tagKey, fieldName string) string {
+			names = append(names, fieldName)
+			return "MYAPP_" + strings.ToUpper(tagKey)
+		},
+	}
+
+	data, err := opts.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "MYAPP_HOST=localhost\nMYAPP_PORT=8080\nMYAPP_LIMIT_US=100\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if expected := []string{"Host", "Port", "Limits"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected field names %v, got %v", expected, names)
+	}
+}
+
+func TestMarshalTrailingNewline(t *testing.T) {
+	cfg := struct {
+		Host string `env:"TEST_HOST"`
+		Port int    `env:"TEST_PORT"`
+	}{Host: "localhost", Port: 8080}
+
+	t.Run("exactly one by default", func(t *testing.T) {
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(data); got != "TEST_HOST=localhost\nTEST_PORT=8080\n" {
+			t.Errorf("unexpected output: %q", got)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		data, err := dotenv.MarshalOptions{OmitTrailingNewline: true}.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(data); got != "TEST_HOST=localhost\nTEST_PORT=8080" {
+			t.Errorf("unexpected output: %q", got)
+		}
+	})
+
+	t.Run("empty struct", func(t *testing.T) {
+		for _, opts := range []dotenv.MarshalOptions{{}, {OmitTrailingNewline: true}} {
+			data, err := opts.Marshal(struct{ Untagged string }{Untagged: "x"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if data == nil || len(data) != 0 {
+				t.Errorf("expected an empty, non-nil slice, got %#v", data)
+			}
+		}
+	})
+}
+
+func TestMarshalMapOrdered(t *testing.T) {
+	vars := map[string]string{
+		"TEST_PORT":  "8080",
+		"TEST_HOST":  "localhost",
+		"TEST_DEBUG": "true",
+		"TEST_EXTRA": "b",
+		"TEST_ALPHA": "a",
+		"TEST_NAME":  "two words",
+	}
+
+	order := []string{"TEST_PORT", "TEST_NAME", "TEST_MISSING", "TEST_HOST", "TEST_PORT", "TEST_DEBUG"}
+	data, err := dotenv.MarshalMapOrdered(vars, order)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "TEST_PORT=8080\nTEST_NAME=\"two words\"\nTEST_HOST=localhost\nTEST_DEBUG=true\nTEST_ALPHA=a\nTEST_EXTRA=b\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", expected)}}
+		entries, err := loader.ParseWithSource()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		parsed := make(map[string]string)
+		var keys []string
+		for _, entry := range entries {
+			parsed[entry.Key] = entry.Value
+			keys = append(keys, entry.Key)
+		}
+
+		again, err := dotenv.MarshalMapOrdered(parsed, keys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(again) != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, again)
+		}
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		if _, err := dotenv.MarshalMapOrdered(map[string]string{"": "x"}, nil); err == nil {
+			t.Error("expected error for empty key")
+		}
+	})
+}
+
+func TestUnmarshalFloat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+		wantErr  bool
+	}{
+		{value: "1.5", expected: 1.5},
+		{value: "-2.5", expected: -2.5},
+		{value: "+1.5", expected: 1.5},
+		{value: ".5", expected: 0.5},
+		{value: "1.", expected: 1},
+		{value: "1.5e10", expected: 1.5e10},
+		{value: "2.5E-3", expected: 2.5e-3},
+		{value: "-1e+2", expected: -100},
+		{value: "Inf", wantErr: true},
+		{value: "-Inf", wantErr: true},
+		{value: "+Infinity", wantErr: true},
+		{value: "NaN", wantErr: true},
+		{value: "1e400", wantErr: true},
+		{value: "1.5.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("TEST_FLOAT", tt.value)
+
+			var cfg struct {
+				Value float64 `env:"TEST_FLOAT"`
+			}
+
+			err := dotenv.Unmarshal(&cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", cfg.Value)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Value != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, cfg.Value)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInt(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  string
+	}{
+		{value: "100", expected: 100},
+		{value: "+100", expected: 100},
+		{value: "-100", expected: -100},
+		{value: "1_000", expected: 1000},
+		{value: "+1_000_000", expected: 1000000},
+		{value: "-1_000", expected: -1000},
+		{value: "0", expected: 0},
+		{value: "_100", wantErr: "invalid syntax"},
+		{value: "100_", wantErr: "invalid syntax"},
+		{value: "1__000", wantErr: "invalid syntax"},
+		{value: "+_1", wantErr: "invalid syntax"},
+		{value: "++1", wantErr: "invalid syntax"},
+		{value: "+-1", wantErr: "invalid syntax"},
+		{value: "+", wantErr: "invalid syntax"},
+		{value: "9_223_372_036_854_775_808", wantErr: "value out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("TEST_INT", tt.value)
+
+			var cfg struct {
+				Value int64 `env:"TEST_INT"`
+			}
+
+			err := dotenv.Unmarshal(&cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Value != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, cfg.Value)
+			}
+		})
+	}
+}
+
+func TestUnmarshalUint(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected uint16
+		wantErr  string
+	}{
+		{value: "8080", expected: 8080},
+		{value: "+8080", expected: 8080},
+		{value: "65_535", expected: 65535},
+		{value: "-0", wantErr: "negative value for unsigned type"},
+		{value: "-1", wantErr: "negative value for unsigned type"},
+		{value: "-1_000", wantErr: "negative value for unsigned type"},
+		{value: "65_536", wantErr: "value out of range"},
+		{value: "1_", wantErr: "invalid syntax"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("TEST_UINT", tt.value)
+
+			var cfg struct {
+				Value uint16 `env:"TEST_UINT"`
+			}
+
+			err := dotenv.Unmarshal(&cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Value != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, cfg.Value)
+			}
+		})
+	}
+}
+
+func TestUnmarshalFromMap(t *testing.T) {
+	vars := map[string]string{
+		"TEST_HOST":  "localhost",
+		"TEST_PORT":  "8080",
+		"TEST_DEBUG": "true",
+		"TEST_RATE":  "1.5",
+	}
+
+	var cfg ConfigTest
+	if err := dotenv.UnmarshalFromMap(vars, &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Host != "localhost" || cfg.Port != 8080 || !cfg.Debug || cfg.RateLimit != 1.5 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("reads file into struct", func(t *testing.T) {
+		os.Unsetenv("TEST_HOST")
+		os.Unsetenv("TEST_PORT")
+		os.Unsetenv("TEST_DEBUG")
+		os.Unsetenv("TEST_RATE")
+
+		path := writeEnvFile(t, ".env", "TEST_HOST=localhost\nTEST_PORT=8080\nTEST_DEBUG=true\nTEST_RATE=${TEST_PORT}.5\n")
+
+		var cfg ConfigTest
+		if err := dotenv.Decode(&cfg, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Host != "localhost" || cfg.Port != 8080 || !cfg.Debug || cfg.RateLimit != 8080.5 {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+
+		if _, ok := os.LookupEnv("TEST_HOST"); ok {
+			t.Error("Decode should not set the process environment")
 		}
 	})
 
-	t.Run("non struct returns error", func(t *testing.T) {
-		if _, err := dotenv.Marshal("invalid"); err == nil {
-			t.Fatal("expected error for non-struct, got nil")
+	t.Run("process env takes precedence", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "from-env")
+
+		path := writeEnvFile(t, ".env", "TEST_HOST=localhost\nTEST_PORT=8080\nTEST_DEBUG=true\nTEST_RATE=1\n")
+
+		var cfg ConfigTest
+		if err := dotenv.Decode(&cfg, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Host != "from-env" {
+			t.Errorf("Host: expected %q, got %q", "from-env", cfg.Host)
+		}
+	})
+
+	t.Run("missing file returns error", func(t *testing.T) {
+		var cfg ConfigTest
+		if err := dotenv.Decode(&cfg, "does-not-exist.env"); err == nil {
+			t.Fatal("expected error for missing file, got nil")
+		}
+	})
+}
+
+func TestMarshalDelta(t *testing.T) {
+	baseline := ConfigTest{Host: "localhost", Port: 8080, Debug: false, RateLimit: 1.5}
+
+	t.Run("only changed fields", func(t *testing.T) {
+		current := baseline
+		current.Port = 9090
+		current.Debug = true
+
+		data, err := dotenv.MarshalDelta(&current, baseline)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "TEST_PORT=9090\nTEST_DEBUG=true\n"; string(data) != expected {
+			t.Errorf("expected %q, got %q", expected, data)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		data, err := dotenv.MarshalDelta(baseline, &baseline)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(data) != 0 {
+			t.Errorf("expected empty output, got %q", data)
+		}
+	})
+
+	t.Run("mismatched types", func(t *testing.T) {
+		if _, err := dotenv.MarshalDelta(baseline, ConfigWithDefault{}); err == nil {
+			t.Fatal("expected type error, got nil")
+		}
+	})
+}
+
+func TestUnmarshalAll(t *testing.T) {
+	t.Setenv("TEST_HOST", "localhost")
+	t.Setenv("TEST_PORT", "eighty")
+	t.Setenv("TEST_DEBUG", "maybe")
+	t.Setenv("TEST_RATE", "fast")
+
+	var cfg ConfigTest
+	err := dotenv.UnmarshalAll(&cfg)
+	if err == nil {
+		t.Fatal("expected errors, got nil")
+	}
+
+	for _, name := range []string{"Port", "Debug", "RateLimit"} {
+		if !strings.Contains(err.Error(), "error setting field "+name) {
+			t.Errorf("expected error for %s, got %v", name, err)
+		}
+	}
+
+	if cfg.Host != "localhost" {
+		t.Errorf("expected valid field to be set, got Host %q", cfg.Host)
+	}
+
+	t.Run("Unmarshal stops at the first error", func(t *testing.T) {
+		err := dotenv.Unmarshal(&ConfigTest{})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		if strings.Contains(err.Error(), "Debug") {
+			t.Errorf("expected only the first error, got %v", err)
 		}
 	})
 }
@@ -0,0 +1,85 @@
+package dotenv_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type ConfigEmpty struct {
+	Greeting string `env:"TEST_EMPTY_GREETING" default:"hello"`
+	Token    string `env:"TEST_EMPTY_TOKEN" required:"true"`
+	Port     int    `env:"TEST_EMPTY_PORT" default:"8080"`
+}
+
+func TestUnmarshalTreatEmptyAsSet(t *testing.T) {
+	opts := dotenv.UnmarshalOptions{TreatEmptyAsSet: true}
+
+	t.Run("present but empty", func(t *testing.T) {
+		t.Setenv("TEST_EMPTY_GREETING", "")
+		t.Setenv("TEST_EMPTY_TOKEN", "")
+		t.Setenv("TEST_EMPTY_PORT", "")
+
+		cfg := ConfigEmpty{Greeting: "stale"}
+		if err := opts.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Greeting != "" {
+			t.Errorf("Greeting: expected empty string, got %q", cfg.Greeting)
+		}
+
+		if cfg.Port != 8080 {
+			t.Errorf("Port: expected default 8080 for a non-string field, got %d", cfg.Port)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		os.Unsetenv("TEST_EMPTY_GREETING")
+		t.Setenv("TEST_EMPTY_TOKEN", "secret")
+		os.Unsetenv("TEST_EMPTY_PORT")
+
+		var cfg ConfigEmpty
+		if err := opts.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Greeting != "hello" {
+			t.Errorf("Greeting: expected default %q, got %q", "hello", cfg.Greeting)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Setenv("TEST_EMPTY_GREETING", "")
+		t.Setenv("TEST_EMPTY_TOKEN", "secret")
+
+		var cfg ConfigEmpty
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Greeting != "hello" {
+			t.Errorf("Greeting: expected default %q, got %q", "hello", cfg.Greeting)
+		}
+	})
+
+	t.Run("from a file", func(t *testing.T) {
+		t.Setenv("TEST_EMPTY_GREETING", "stale")
+		t.Setenv("TEST_EMPTY_TOKEN", "stale")
+
+		path := writeEnvFile(t, ".env", "TEST_EMPTY_GREETING=\nTEST_EMPTY_TOKEN=secret\n")
+		if err := dotenv.Overload(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var cfg ConfigEmpty
+		if err := opts.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Greeting != "" {
+			t.Errorf("Greeting: expected empty string, got %q", cfg.Greeting)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package dotenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestParseEnvironFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "environ")
+	content := "HOME=/root\x00GREETING=hello world\x00EQUATION=a=b\x00EMPTY=\x00junk\x00HOME=/home/app\x00"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vars, err := dotenv.ParseEnvironFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"HOME":     "/home/app",
+		"GREETING": "hello world",
+		"EQUATION": "a=b",
+		"EMPTY":    "",
+	}
+
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("expected %v, got %v", expected, vars)
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := dotenv.ParseEnvironFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Fatal("expected error for missing file, got nil")
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package dotenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestWriteExample(t *testing.T) {
+	t.Run("merges existing values", func(t *testing.T) {
+		path := writeEnvFile(t, ".env.example", "TEST_HOST=db.example.com\nTEST_REMOVED=old\nTEST_PORT=5432\n")
+
+		if err := dotenv.WriteExample(&ConfigTest{}, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "TEST_HOST=db.example.com\nTEST_PORT=5432\nTEST_DEBUG=\nTEST_RATE=\n"
+		if got := string(data); got != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("creates missing file with defaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env.example")
+
+		if err := dotenv.WriteExample(ConfigWithDefault{}, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "TEST_AUTH=xxx\nTEST_NAME=guest\n"
+		if got := string(data); got != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package dotenv_test
+
+import (
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestUnmarshalExpandOption(t *testing.T) {
+	t.Setenv("TEST_EXPAND_OPT_USER", "gopher")
+	t.Setenv("TEST_EXPAND_OPT_GREETING", "Hello ${TEST_EXPAND_OPT_USER}")
+	t.Setenv("TEST_EXPAND_OPT_LITERAL", "Hello ${TEST_EXPAND_OPT_USER}")
+
+	var cfg struct {
+		Greeting string `env:"TEST_EXPAND_OPT_GREETING,expand"`
+		Literal  string `env:"TEST_EXPAND_OPT_LITERAL"`
+		Home     string `env:"TEST_EXPAND_OPT_HOME,expand" default:"/home/$TEST_EXPAND_OPT_USER"`
+	}
+
+	if err := dotenv.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Greeting != "Hello gopher" {
+		t.Errorf("Greeting: expected %q, got %q", "Hello gopher", cfg.Greeting)
+	}
+
+	if cfg.Literal != "Hello ${TEST_EXPAND_OPT_USER}" {
+		t.Errorf("Literal: expected the reference kept, got %q", cfg.Literal)
+	}
+
+	if cfg.Home != "/home/gopher" {
+		t.Errorf("Home: expected the default expanded, got %q", cfg.Home)
+	}
+
+	t.Run("key is unchanged", func(t *testing.T) {
+		keys := dotenv.Keys(cfg)
+		if len(keys) != 3 || keys[0] != "TEST_EXPAND_OPT_GREETING" {
+			t.Errorf("unexpected keys: %v", keys)
+		}
+	})
+}
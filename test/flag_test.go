@@ -0,0 +1,70 @@
+package dotenv_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type FlagConfig struct {
+	Beta     bool `env:"TEST_FLAGS" flag:"beta"`
+	NewUI    bool `env:"TEST_FLAGS" flag:"newui"`
+	FastPath bool `env:"TEST_FLAGS" flag:"fastpath"`
+	Legacy   bool `env:"TEST_FLAGS" flag:"legacy"`
+	Port     int  `env:"TEST_PORT"`
+}
+
+func TestUnmarshalFlags(t *testing.T) {
+	t.Setenv("TEST_FLAGS", "beta, newui,fastpath")
+	t.Setenv("TEST_PORT", "8080")
+
+	var cfg FlagConfig
+	if err := dotenv.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := FlagConfig{Beta: true, NewUI: true, FastPath: true, Port: 8080}
+	if cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+
+	t.Run("marshal joins set flags", func(t *testing.T) {
+		data, err := dotenv.Marshal(&FlagConfig{NewUI: true, Legacy: true, Port: 8080})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := "TEST_FLAGS=newui,legacy\nTEST_PORT=8080\n"; string(data) != want {
+			t.Errorf("expected %q, got %q", want, data)
+		}
+	})
+
+	t.Run("marshal fields joins set flags", func(t *testing.T) {
+		data, err := dotenv.MarshalFields(FlagConfig{Beta: true, FastPath: true}, "TEST_FLAGS")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := "TEST_FLAGS=beta,fastpath\n"; string(data) != want {
+			t.Errorf("expected %q, got %q", want, data)
+		}
+	})
+
+	t.Run("keys lists shared variable once", func(t *testing.T) {
+		want := []string{"TEST_FLAGS", "TEST_PORT"}
+		if got := dotenv.Keys(FlagConfig{}); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("non bool field", func(t *testing.T) {
+		var bad struct {
+			Beta string `env:"TEST_FLAGS" flag:"beta"`
+		}
+
+		if err := dotenv.Unmarshal(&bad); err == nil {
+			t.Fatal("expected flag error, got nil")
+		}
+	})
+}
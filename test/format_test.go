@@ -0,0 +1,381 @@
+package dotenv_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestUnmarshalFormatBoolInt(t *testing.T) {
+	tests := map[string]int{
+		"true":  1,
+		"false": 0,
+		"1":     1,
+		"0":     0,
+		"2":     2,
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("TEST_FEATURE", value)
+
+			var cfg struct {
+				Feature int `env:"TEST_FEATURE" format:"boolint"`
+			}
+
+			if err := dotenv.Unmarshal(&cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Feature != expected {
+				t.Errorf("expected %d, got %d", expected, cfg.Feature)
+			}
+		})
+	}
+
+	t.Run("without format true is an error", func(t *testing.T) {
+		t.Setenv("TEST_FEATURE", "true")
+
+		var cfg struct {
+			Feature int `env:"TEST_FEATURE"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected parse error, got nil")
+		}
+	})
+
+	t.Run("unknown format returns error", func(t *testing.T) {
+		t.Setenv("TEST_FEATURE", "1")
+
+		var cfg struct {
+			Feature int `env:"TEST_FEATURE" format:"unknown"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected unknown format error, got nil")
+		}
+	})
+}
+
+func TestUnmarshalBytes(t *testing.T) {
+	type BytesConfig struct {
+		Raw  []byte `env:"TEST_BYTES_RAW"`
+		Salt []byte `env:"TEST_BYTES_SALT" format:"base64"`
+	}
+
+	t.Run("raw and base64", func(t *testing.T) {
+		t.Setenv("TEST_BYTES_RAW", "a,b,c")
+		t.Setenv("TEST_BYTES_SALT", "AAEC/w==")
+
+		var cfg BytesConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(cfg.Raw) != "a,b,c" {
+			t.Errorf("Raw: expected %q, got %q", "a,b,c", cfg.Raw)
+		}
+
+		if expected := []byte{0, 1, 2, 255}; !bytes.Equal(cfg.Salt, expected) {
+			t.Errorf("Salt: expected %v, got %v", expected, cfg.Salt)
+		}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "TEST_BYTES_RAW=a,b,c\nTEST_BYTES_SALT=AAEC/w==\n"; string(data) != expected {
+			t.Errorf("Marshal: expected %q, got %q", expected, data)
+		}
+	})
+
+	t.Run("invalid base64 returns error", func(t *testing.T) {
+		t.Setenv("TEST_BYTES_SALT", "not base64!")
+
+		var cfg BytesConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected base64 error, got nil")
+		}
+	})
+}
+
+func TestFormatJSON(t *testing.T) {
+	type JSONConfig struct {
+		Features map[string]int  `env:"TEST_FEATURES" format:"json"`
+		Labels   []string        `env:"TEST_LABELS" format:"json"`
+		Raw      json.RawMessage `env:"TEST_RAW"`
+		Name     string          `env:"TEST_JSON_NAME" format:"json"`
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		in := JSONConfig{
+			Features: map[string]int{"a": 1, "b": 2},
+			Labels:   []string{"blue green", "red"},
+			Raw:      json.RawMessage(`{"x":[1,2]}`),
+			Name:     "abc",
+		}
+
+		data, err := dotenv.Marshal(&in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := `TEST_FEATURES="{\"a\":1,\"b\":2}"` + "\n" +
+			`TEST_LABELS="[\"blue green\",\"red\"]"` + "\n" +
+			`TEST_RAW="{\"x\":[1,2]}"` + "\n" +
+			`TEST_JSON_NAME="\"abc\""` + "\n"
+		if string(data) != expected {
+			t.Errorf("Marshal: expected %q, got %q", expected, data)
+		}
+
+		vars, err := (&dotenv.Loader{}).ParseReaders(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var out JSONConfig
+		if err := dotenv.UnmarshalFromMap(vars, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("expected %+v, got %+v", in, out)
+		}
+	})
+
+	t.Run("invalid json returns error", func(t *testing.T) {
+		t.Setenv("TEST_FEATURES", "{not json")
+
+		var cfg JSONConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected json error, got nil")
+		}
+	})
+}
+
+func TestFormatPercent(t *testing.T) {
+	type PercentConfig struct {
+		Sampling float64 `env:"TEST_SAMPLING" format:"percent"`
+	}
+
+	tests := map[string]float64{
+		"10%":  0.1,
+		"100%": 1.0,
+		"2.5%": 0.025,
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("TEST_SAMPLING", value)
+
+			var cfg PercentConfig
+			if err := dotenv.Unmarshal(&cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Sampling != expected {
+				t.Errorf("expected %v, got %v", expected, cfg.Sampling)
+			}
+
+			data, err := dotenv.Marshal(&cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want := "TEST_SAMPLING=" + value + "\n"; string(data) != want {
+				t.Errorf("Marshal: expected %q, got %q", want, data)
+			}
+		})
+	}
+
+	t.Run("without format percent sign is an error", func(t *testing.T) {
+		t.Setenv("TEST_SAMPLING", "10%")
+
+		var cfg struct {
+			Sampling float64 `env:"TEST_SAMPLING"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected parse error, got nil")
+		}
+	})
+
+	t.Run("non float field", func(t *testing.T) {
+		t.Setenv("TEST_SAMPLING", "10%")
+
+		var cfg struct {
+			Sampling int `env:"TEST_SAMPLING" format:"percent"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected format error, got nil")
+		}
+	})
+}
+
+func TestFormatAutoTime(t *testing.T) {
+	type TimeConfig struct {
+		Since time.Time `env:"TEST_SINCE" format:"auto"`
+	}
+
+	tests := map[string]time.Time{
+		"2024-03-01T10:30:00Z":      time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		"2024-03-01T10:30:00+02:00": time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+		"2024-03-01":                time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2024-03-01 10:30:00":       time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		"1709289000":                time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("TEST_SINCE", value)
+
+			var cfg TimeConfig
+			if err := dotenv.Unmarshal(&cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !cfg.Since.Equal(expected) {
+				t.Errorf("expected %v, got %v", expected, cfg.Since)
+			}
+		})
+	}
+
+	t.Run("marshal writes RFC 3339", func(t *testing.T) {
+		cfg := TimeConfig{Since: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "TEST_SINCE=2024-03-01T10:30:00Z\n"; string(data) != expected {
+			t.Errorf("expected %q, got %q", expected, data)
+		}
+	})
+
+	t.Run("unparseable value lists layouts", func(t *testing.T) {
+		t.Setenv("TEST_SINCE", "next tuesday")
+
+		var cfg TimeConfig
+		err := dotenv.Unmarshal(&cfg)
+		if err == nil {
+			t.Fatal("expected time error, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "2006-01-02 15:04:05") {
+			t.Errorf("expected attempted layouts in error, got %v", err)
+		}
+	})
+}
+
+func TestFormatChar(t *testing.T) {
+	t.Run("char mode", func(t *testing.T) {
+		tests := map[string]rune{"A": 'A', ";": ';', "é": 'é', "xyz": 'x'}
+
+		for value, expected := range tests {
+			t.Setenv("TEST_LEVEL", value)
+
+			var cfg struct {
+				Level rune `env:"TEST_LEVEL" format:"char"`
+			}
+
+			if err := dotenv.Unmarshal(&cfg); err != nil {
+				t.Fatalf("unexpected error for %q: %v", value, err)
+			}
+
+			if cfg.Level != expected {
+				t.Errorf("%q: expected %q, got %q", value, expected, cfg.Level)
+			}
+		}
+	})
+
+	t.Run("numeric mode", func(t *testing.T) {
+		t.Setenv("TEST_LEVEL", "65")
+
+		var cfg struct {
+			Level rune `env:"TEST_LEVEL"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Level != 65 {
+			t.Errorf("expected 65, got %d", cfg.Level)
+		}
+
+		t.Setenv("TEST_LEVEL", "A")
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected parse error for A without format, got nil")
+		}
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		cfg := struct {
+			Level rune `env:"TEST_LEVEL" format:"char"`
+		}{Level: 'W'}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "TEST_LEVEL=W\n"; string(data) != expected {
+			t.Errorf("expected %q, got %q", expected, data)
+		}
+	})
+}
+
+func TestFormatPathList(t *testing.T) {
+	type PathConfig struct {
+		Paths []string `env:"TEST_BIN_PATHS" format:"pathlist"`
+	}
+
+	sep := string(os.PathListSeparator)
+	paths := []string{"/usr/local/bin", "/opt/app bin", "/usr/bin"}
+
+	t.Run("unmarshal", func(t *testing.T) {
+		t.Setenv("TEST_BIN_PATHS", strings.Join(paths, sep))
+
+		var cfg PathConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(cfg.Paths, paths) {
+			t.Errorf("expected %v, got %v", paths, cfg.Paths)
+		}
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		data, err := dotenv.Marshal(PathConfig{Paths: paths})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "TEST_BIN_PATHS=\"" + strings.Join(paths, sep) + "\"\n"; string(data) != expected {
+			t.Errorf("expected %q, got %q", expected, data)
+		}
+	})
+
+	t.Run("non slice field", func(t *testing.T) {
+		t.Setenv("TEST_BIN_PATHS", "/usr/bin")
+
+		var cfg struct {
+			Paths string `env:"TEST_BIN_PATHS" format:"pathlist"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected format error, got nil")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package dotenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestFreeze(t *testing.T) {
+	t.Setenv("TEST_FROZEN_HOST", "")
+	t.Setenv("TEST_FROZEN_PORT", "")
+	os.Unsetenv("TEST_FROZEN_HOST")
+	os.Unsetenv("TEST_FROZEN_PORT")
+
+	path := writeEnvFile(t, ".env", "TEST_FROZEN_HOST=localhost\nTEST_FROZEN_PORT=8080\n")
+	if err := dotenv.Load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dotenv.Freeze()
+
+	if err := dotenv.VerifyUnchanged(); err != nil {
+		t.Fatalf("unexpected error before mutation: %v", err)
+	}
+
+	os.Setenv("TEST_FROZEN_HOST", "evil.example.com")
+	os.Unsetenv("TEST_FROZEN_PORT")
+
+	err := dotenv.VerifyUnchanged()
+	if err == nil {
+		t.Fatal("expected drift error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "TEST_FROZEN_HOST, TEST_FROZEN_PORT") {
+		t.Errorf("expected both variables to be listed, got %v", err)
+	}
+
+	dotenv.Freeze()
+	if err := dotenv.VerifyUnchanged(); err != nil {
+		t.Errorf("expected a new snapshot to accept the current values, got %v", err)
+	}
+}
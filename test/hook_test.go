@@ -0,0 +1,49 @@
+package dotenv_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type DSNConfig struct {
+	Host string `env:"TEST_DSN_HOST" default:"localhost"`
+	Port int    `env:"TEST_DSN_PORT" default:"5432"`
+	Name string `env:"TEST_DSN_NAME"`
+	DSN  string
+}
+
+func (c *DSNConfig) AfterUnmarshal() error {
+	if c.Name == "" {
+		return errors.New("database name is empty")
+	}
+
+	c.DSN = fmt.Sprintf("postgres://%s:%d/%s", c.Host, c.Port, c.Name)
+	return nil
+}
+
+func TestAfterUnmarshal(t *testing.T) {
+	t.Run("computes derived field", func(t *testing.T) {
+		t.Setenv("TEST_DSN_NAME", "app")
+
+		var cfg DSNConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "postgres://localhost:5432/app"; cfg.DSN != expected {
+			t.Errorf("DSN: expected %q, got %q", expected, cfg.DSN)
+		}
+	})
+
+	t.Run("propagates hook error", func(t *testing.T) {
+		t.Setenv("TEST_DSN_NAME", "")
+
+		var cfg DSNConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected hook error, got nil")
+		}
+	})
+}
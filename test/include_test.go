@@ -0,0 +1,169 @@
+package dotenv_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+// writeEnvFiles writes files into one temporary directory and returns it.
+func writeEnvFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoaderInclude(t *testing.T) {
+	t.Run("simple include", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{
+			"shared/base.env": "TEST_INC_HOST=base\nTEST_INC_PORT=5432\n",
+			".env":            "TEST_INC_HOST=early\n@include shared/base.env\nTEST_INC_PORT=6543\n",
+		})
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, ".env")}}
+		entries, err := loader.ParseWithSource()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		vars := make(map[string]string)
+		for _, entry := range entries {
+			vars[entry.Key] = entry.Value
+		}
+
+		if vars["TEST_INC_HOST"] != "base" {
+			t.Errorf("TEST_INC_HOST: expected the included value to override earlier lines, got %q", vars["TEST_INC_HOST"])
+		}
+
+		if vars["TEST_INC_PORT"] != "6543" {
+			t.Errorf("TEST_INC_PORT: expected the including file to override, got %q", vars["TEST_INC_PORT"])
+		}
+
+		if entries[1].File != filepath.Join(dir, "shared/base.env") || entries[1].Line != 1 {
+			t.Errorf("expected included entry from base.env:1, got %s:%d", entries[1].File, entries[1].Line)
+		}
+	})
+
+	t.Run("nested include is relative to the including file", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{
+			"a/b/leaf.env": "TEST_INC_LEAF=leaf\n",
+			"a/mid.env":    "@include b/leaf.env\n",
+			".env":         "@include a/mid.env\n",
+		})
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, ".env")}}
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_INC_LEAF"] != "leaf" {
+			t.Errorf("expected %q, got %q", "leaf", vars["TEST_INC_LEAF"])
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{
+			"a.env": "TEST_INC_A=1\n@include b.env\n",
+			"b.env": "@include a.env\n",
+		})
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, "a.env")}}
+		_, err := loader.Parse()
+
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "include cycle") {
+			t.Fatalf("expected include cycle error, got %v", err)
+		}
+
+		if parseErr.File != filepath.Join(dir, "b.env") || parseErr.Line != 1 {
+			t.Errorf("expected error at b.env:1, got %s:%d", parseErr.File, parseErr.Line)
+		}
+	})
+
+	t.Run("self include", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{".env": "@include .env\n"})
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, ".env")}}
+		if _, err := loader.Parse(); err == nil || !strings.Contains(err.Error(), "include cycle") {
+			t.Fatalf("expected include cycle error, got %v", err)
+		}
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		files := make(map[string]string)
+		for i := 0; i < 40; i++ {
+			files[fmt.Sprintf("%d.env", i)] = fmt.Sprintf("@include %d.env\n", i+1)
+		}
+		dir := writeEnvFiles(t, files)
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, "0.env")}}
+		if _, err := loader.Parse(); err == nil || !strings.Contains(err.Error(), "nested deeper") {
+			t.Fatalf("expected depth error, got %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{".env": "TEST_INC_A=1\n@include missing.env\n"})
+
+		loader := dotenv.Loader{AllowIncludes: true, Filenames: []string{filepath.Join(dir, ".env")}}
+		var parseErr *dotenv.ParseError
+		if _, err := loader.Parse(); !errors.As(err, &parseErr) || parseErr.Line != 2 {
+			t.Fatalf("expected ParseError at line 2, got %v", err)
+		}
+	})
+	t.Run("disabled by default", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{
+			"base.env": "TEST_INC_HOST=base\n",
+			".env":     "@include base.env\nTEST_INC_PORT=6543\n",
+		})
+
+		loader := dotenv.Loader{Filenames: []string{filepath.Join(dir, ".env")}}
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := vars["TEST_INC_HOST"]; ok || vars["TEST_INC_PORT"] != "6543" {
+			t.Errorf("expected the @include line to be skipped, got %v", vars)
+		}
+	})
+
+	t.Run("readers and arguments", func(t *testing.T) {
+		dir := writeEnvFiles(t, map[string]string{"base.env": "TEST_INC_HOST=base\n"})
+		line := "@include " + filepath.Join(dir, "base.env")
+
+		loader := dotenv.Loader{AllowIncludes: true}
+		vars, err := loader.ParseReaders(strings.NewReader(line + "\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := vars["TEST_INC_HOST"]; ok {
+			t.Errorf("expected a reader's @include line to be ignored, got %v", vars)
+		}
+
+		vars, err = loader.ParseArgs([]string{line + " TEST_INC_PORT=1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := vars["TEST_INC_HOST"]; ok {
+			t.Errorf("expected an argument's @include to be ignored, got %v", vars)
+		}
+	})
+}
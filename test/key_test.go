@@ -0,0 +1,121 @@
+package dotenv_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestUnmarshalKey(t *testing.T) {
+	t.Run("int", func(t *testing.T) {
+		t.Setenv("TEST_KEY_PORT", "8080")
+
+		port, err := dotenv.UnmarshalKey[int]("TEST_KEY_PORT")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if port != 8080 {
+			t.Errorf("expected 8080, got %d", port)
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		t.Setenv("TEST_KEY_TIMEOUT", "1m30s")
+
+		timeout, err := dotenv.UnmarshalKey[time.Duration]("TEST_KEY_TIMEOUT")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if timeout != 90*time.Second {
+			t.Errorf("expected 1m30s, got %v", timeout)
+		}
+	})
+
+	t.Run("custom type", func(t *testing.T) {
+		t.Setenv("TEST_KEY_REGION", "EU")
+
+		region, err := dotenv.UnmarshalKey[Region]("TEST_KEY_REGION")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if region != "EU" {
+			t.Errorf("expected EU, got %q", region)
+		}
+
+		t.Setenv("TEST_KEY_REGION", "MARS")
+		if _, err := dotenv.UnmarshalKey[Region]("TEST_KEY_REGION"); err == nil {
+			t.Fatal("expected error for unknown region, got nil")
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("TEST_KEY_PORT", "eighty")
+
+		if _, err := dotenv.UnmarshalKey[int]("TEST_KEY_PORT"); err == nil {
+			t.Fatal("expected parse error, got nil")
+		}
+	})
+
+	t.Run("unset key", func(t *testing.T) {
+		os.Unsetenv("TEST_KEY_MISSING")
+
+		if _, err := dotenv.UnmarshalKey[int]("TEST_KEY_MISSING"); err == nil {
+			t.Fatal("expected error for unset key, got nil")
+		}
+	})
+}
+
+func TestEnviron(t *testing.T) {
+	t.Run("fills a struct by value", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "localhost")
+		t.Setenv("TEST_PORT", "8080")
+		t.Setenv("TEST_DEBUG", "true")
+		t.Setenv("TEST_RATE", "1.5")
+
+		cfg, err := dotenv.Environ[ConfigTest]()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Host != "localhost" || cfg.Port != 8080 || !cfg.Debug || cfg.RateLimit != 1.5 {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+	})
+
+	t.Run("ignores the store", func(t *testing.T) {
+		dotenv.ClearStore()
+		t.Cleanup(dotenv.ClearStore)
+		os.Unsetenv("TEST_ENVIRON_NAME")
+		dotenv.Set("TEST_ENVIRON_NAME", "stored")
+
+		cfg, err := dotenv.Environ[struct {
+			Name string `env:"TEST_ENVIRON_NAME" default:"fallback"`
+		}]()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Name != "fallback" {
+			t.Errorf("expected %q, got %q", "fallback", cfg.Name)
+		}
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		os.Unsetenv("TEST_HOST")
+
+		if _, err := dotenv.Environ[ConfigTest](); err == nil {
+			t.Fatal("expected error for missing required field, got nil")
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := dotenv.Environ[int](); err == nil {
+			t.Fatal("expected error for non-struct type, got nil")
+		}
+	})
+}
@@ -0,0 +1,1381 @@
+package dotenv_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+// writeEnvFile writes content to a file named name inside a temporary
+// directory and returns its path.
+func writeEnvFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoaderExpand(t *testing.T) {
+	t.Setenv("TEST_EXPAND_USER", "gopher")
+
+	content := `
+TEST_HOST=localhost
+TEST_URL=http://${TEST_HOST}:8080
+TEST_GREETING=hello $TEST_EXPAND_USER
+TEST_FALLBACK=${TEST_MISSING:-fallback}
+TEST_LITERAL='$TEST_HOST'
+TEST_ESCAPED=\$TEST_HOST
+TEST_DOUBLE_DOLLAR=cost $$5
+TEST_DOUBLE_DOLLAR_VAR=$$TEST_HOST
+TEST_CRON="*/5 * * * * echo $$"
+`
+
+	loader := dotenv.Loader{
+		Filenames: []string{writeEnvFile(t, ".env", content)},
+		Expand:    true,
+	}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]string{
+		"TEST_URL":               "http://localhost:8080",
+		"TEST_GREETING":          "hello gopher",
+		"TEST_FALLBACK":          "fallback",
+		"TEST_LITERAL":           "$TEST_HOST",
+		"TEST_ESCAPED":           "$TEST_HOST",
+		"TEST_DOUBLE_DOLLAR":     "cost $5",
+		"TEST_DOUBLE_DOLLAR_VAR": "$TEST_HOST",
+		"TEST_CRON":              "*/5 * * * * echo $",
+	}
+
+	for key, expected := range tests {
+		if got := vars[key]; got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+func TestLoaderWithoutExpand(t *testing.T) {
+	loader := dotenv.Loader{
+		Filenames: []string{writeEnvFile(t, ".env", "TEST_HOST=localhost\nTEST_URL=${TEST_HOST}\n")},
+	}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := vars["TEST_URL"]; got != "${TEST_HOST}" {
+		t.Errorf("TEST_URL: expected %q, got %q", "${TEST_HOST}", got)
+	}
+}
+
+func TestLoaderQuotedWhitespace(t *testing.T) {
+	content := "TEST_DOUBLE=\"  padded  \"\nTEST_SINGLE='\tpadded\t'\nTEST_UNQUOTED=  trimmed  \n"
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]string{
+		"TEST_DOUBLE":   "  padded  ",
+		"TEST_SINGLE":   "\tpadded\t",
+		"TEST_UNQUOTED": "trimmed",
+	}
+
+	for key, expected := range tests {
+		if got := vars[key]; got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+func TestLoaderExpandWithOSSemantics(t *testing.T) {
+	t.Setenv("TEST_EXPAND_USER", "gopher")
+
+	content := `
+TEST_HOST=localhost
+TEST_URL=http://${TEST_HOST}:8080
+TEST_GREETING=hello $TEST_EXPAND_USER
+TEST_FALLBACK=${TEST_MISSING:-fallback}
+`
+	path := writeEnvFile(t, ".env", content)
+
+	custom := dotenv.Loader{Filenames: []string{path}, Expand: true}
+	standard := dotenv.Loader{Filenames: []string{path}, ExpandWithOSSemantics: true}
+
+	customVars, err := custom.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	standardVars, err := standard.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"TEST_URL", "TEST_GREETING"} {
+		if customVars[key] != standardVars[key] {
+			t.Errorf("%s: expected both modes to agree, got %q and %q", key, customVars[key], standardVars[key])
+		}
+	}
+
+	if got := customVars["TEST_FALLBACK"]; got != "fallback" {
+		t.Errorf("TEST_FALLBACK: expected %q, got %q", "fallback", got)
+	}
+
+	if got := standardVars["TEST_FALLBACK"]; got != "" {
+		t.Errorf("TEST_FALLBACK: os.Expand has no default syntax, expected %q, got %q", "", got)
+	}
+}
+
+func TestLoaderParseWithSource(t *testing.T) {
+	content := `# header comment
+TEST_PORT=8080 # external port
+TEST_NAME="quoted # not a comment" # display name
+TEST_PLAIN=value
+`
+	path := writeEnvFile(t, ".env", content)
+
+	loader := dotenv.Loader{Filenames: []string{path}}
+
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dotenv.Entry{
+		{Key: "TEST_PORT", Value: "8080", Comment: "external port", File: path, Line: 2},
+		{Key: "TEST_NAME", Value: "quoted # not a comment", Comment: "display name", File: path, Line: 3},
+		{Key: "TEST_PLAIN", Value: "value", File: path, Line: 4},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, entry := range entries {
+		if entry != expected[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entry)
+		}
+	}
+}
+
+func TestLoadAndOverload(t *testing.T) {
+	path := writeEnvFile(t, ".env", "TEST_LOAD_EXISTING=from-file\nTEST_LOAD_NEW=from-file\n")
+
+	t.Run("load keeps existing values", func(t *testing.T) {
+		t.Setenv("TEST_LOAD_EXISTING", "from-env")
+		t.Setenv("TEST_LOAD_NEW", "")
+		os.Unsetenv("TEST_LOAD_NEW")
+
+		if err := dotenv.Load(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := os.Getenv("TEST_LOAD_EXISTING"); got != "from-env" {
+			t.Errorf("TEST_LOAD_EXISTING: expected %q, got %q", "from-env", got)
+		}
+
+		if got := os.Getenv("TEST_LOAD_NEW"); got != "from-file" {
+			t.Errorf("TEST_LOAD_NEW: expected %q, got %q", "from-file", got)
+		}
+	})
+
+	t.Run("overload replaces existing values", func(t *testing.T) {
+		t.Setenv("TEST_LOAD_EXISTING", "from-env")
+		t.Setenv("TEST_LOAD_NEW", "")
+
+		if err := dotenv.Overload(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := os.Getenv("TEST_LOAD_EXISTING"); got != "from-file" {
+			t.Errorf("TEST_LOAD_EXISTING: expected %q, got %q", "from-file", got)
+		}
+	})
+}
+
+func TestLoadMissingFiles(t *testing.T) {
+	t.Run("missing explicit file returns error", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "must-exist.env")
+
+		if err := dotenv.Load(missing); err == nil {
+			t.Fatal("expected error for missing explicit file, got nil")
+		}
+	})
+
+	t.Run("missing default file is skipped", func(t *testing.T) {
+		originalFilenames := dotenv.FilenameVariables
+		dotenv.FilenameVariables = []string{filepath.Join(t.TempDir(), ".env")}
+		defer func() {
+			dotenv.FilenameVariables = originalFilenames
+		}()
+
+		if err := dotenv.Load(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestLoaderCommentChar(t *testing.T) {
+	content := `; ini-style comment
+TEST_PORT=8080 ; external port
+TEST_COLOR=#ff0000
+TEST_QUOTED="a;b" ; inline
+`
+
+	loader := dotenv.Loader{
+		Filenames:   []string{writeEnvFile(t, "settings.ini", content)},
+		CommentChar: ';',
+	}
+
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dotenv.Entry{
+		{Key: "TEST_PORT", Value: "8080", Comment: "external port"},
+		{Key: "TEST_COLOR", Value: "#ff0000"},
+		{Key: "TEST_QUOTED", Value: "a;b", Comment: "inline"},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, entry := range entries {
+		if entry.Key != expected[i].Key || entry.Value != expected[i].Value || entry.Comment != expected[i].Comment {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entry)
+		}
+	}
+}
+
+func TestLoaderQuotedEdgeCases(t *testing.T) {
+	content := `TEST_EMPTY_DOUBLE=""
+TEST_EMPTY_SINGLE=''
+TEST_HASH="#"
+TEST_HASH_SINGLE='#' # comment
+TEST_SPACES="  "
+`
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]string{
+		"TEST_EMPTY_DOUBLE": "",
+		"TEST_EMPTY_SINGLE": "",
+		"TEST_HASH":         "#",
+		"TEST_HASH_SINGLE":  "#",
+		"TEST_SPACES":       "  ",
+	}
+
+	for key, expected := range tests {
+		got, ok := vars[key]
+		if !ok {
+			t.Errorf("%s: expected key to be present", key)
+			continue
+		}
+
+		if got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+func TestLoaderQuotedKey(t *testing.T) {
+	content := `"a=b"=value
+'c=d'="quoted value"
+"unterminated=value
+"spaced" = value
+`
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"a=b": "value",
+		"c=d": "quoted value",
+	}
+
+	if len(vars) != len(expected) {
+		t.Fatalf("expected %d variables, got %d: %v", len(expected), len(vars), vars)
+	}
+
+	for key, value := range expected {
+		if got := vars[key]; got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("explicit directory returns error", func(t *testing.T) {
+		if err := dotenv.Load(dir); err == nil {
+			t.Fatal("expected error for directory, got nil")
+		}
+	})
+
+	t.Run("default directory returns error", func(t *testing.T) {
+		originalFilenames := dotenv.FilenameVariables
+		dotenv.FilenameVariables = []string{filepath.Join(dir, "missing.env"), dir}
+		defer func() {
+			dotenv.FilenameVariables = originalFilenames
+		}()
+
+		if err := dotenv.Load(); err == nil {
+			t.Fatal("expected error for directory, got nil")
+		}
+	})
+
+	t.Run("Collect skips the directory", func(t *testing.T) {
+		t.Setenv("TEST_DIR_LOCAL", "")
+		os.Unsetenv("TEST_DIR_LOCAL")
+
+		originalFilenames := dotenv.FilenameVariables
+		dotenv.FilenameVariables = []string{dir, writeEnvFile(t, ".env.local", "TEST_DIR_LOCAL=loaded\n")}
+		defer func() {
+			dotenv.FilenameVariables = originalFilenames
+		}()
+
+		dotenv.Collect()
+
+		if got := os.Getenv("TEST_DIR_LOCAL"); got != "loaded" {
+			t.Errorf("expected the other file to load, got %q", got)
+		}
+	})
+}
+
+func TestLoaderTemplateValues(t *testing.T) {
+	t.Setenv("TEST_TEMPLATE_USER", "gopher")
+
+	t.Run("renders values", func(t *testing.T) {
+		content := `TEST_HOST=localhost
+TEST_GREETING=Hello {{.TEST_TEMPLATE_USER}}
+TEST_URL="http://{{.TEST_HOST}}:8080"
+TEST_LITERAL='{{.TEST_HOST}}'
+`
+		loader := dotenv.Loader{
+			Filenames:      []string{writeEnvFile(t, ".env", content)},
+			TemplateValues: true,
+		}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		tests := map[string]string{
+			"TEST_GREETING": "Hello gopher",
+			"TEST_URL":      "http://localhost:8080",
+			"TEST_LITERAL":  "{{.TEST_HOST}}",
+		}
+
+		for key, expected := range tests {
+			if got := vars[key]; got != expected {
+				t.Errorf("%s: expected %q, got %q", key, expected, got)
+			}
+		}
+	})
+
+	t.Run("undefined reference returns error", func(t *testing.T) {
+		loader := dotenv.Loader{
+			Filenames:      []string{writeEnvFile(t, ".env", "TEST_GREETING=Hello {{.TEST_TEMPLATE_MISSING}}\n")},
+			TemplateValues: true,
+		}
+
+		if _, err := loader.Parse(); err == nil {
+			t.Fatal("expected error for undefined reference, got nil")
+		}
+	})
+
+	t.Run("invalid template returns error", func(t *testing.T) {
+		loader := dotenv.Loader{
+			Filenames:      []string{writeEnvFile(t, ".env", "TEST_GREETING=Hello {{.TEST_HOST\n")},
+			TemplateValues: true,
+		}
+
+		if _, err := loader.Parse(); err == nil {
+			t.Fatal("expected error for invalid template, got nil")
+		}
+	})
+}
+
+func TestLoaderExportKeyword(t *testing.T) {
+	content := "export TEST_EXPORTED=\"export value\"\nexport export=1\nexport\nexport \nexport =orphan\n"
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"TEST_EXPORTED": "export value",
+		"export":        "1",
+	}
+
+	if len(vars) != len(expected) {
+		t.Fatalf("expected %d variables, got %d: %v", len(expected), len(vars), vars)
+	}
+
+	for key, value := range expected {
+		if got := vars[key]; got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestCollectReaders(t *testing.T) {
+	t.Setenv("TEST_READER_SHARED", "")
+	t.Setenv("TEST_READER_BASE", "")
+
+	base := strings.NewReader("TEST_READER_SHARED=base\nTEST_READER_BASE=kept\n")
+	override := strings.NewReader("TEST_READER_SHARED=override\n")
+
+	if err := dotenv.CollectReaders(base, override); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]string{
+		"TEST_READER_SHARED": "override",
+		"TEST_READER_BASE":   "kept",
+	}
+
+	for key, expected := range tests {
+		if got := os.Getenv(key); got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+func TestLoaderLineContinuation(t *testing.T) {
+	content := "TEST_LONG=part1\\\npart2\\\npart3\nTEST_PATH=C:\\\\\nTEST_NEXT=next\n"
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dotenv.Entry{
+		{Key: "TEST_LONG", Value: "part1part2part3", Line: 1},
+		{Key: "TEST_PATH", Value: "C:\\\\", Line: 4},
+		{Key: "TEST_NEXT", Value: "next", Line: 5},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, entry := range entries {
+		if entry.Key != expected[i].Key || entry.Value != expected[i].Value || entry.Line != expected[i].Line {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entry)
+		}
+	}
+}
+
+func TestLoaderWhitespaceBeforeQuote(t *testing.T) {
+	content := "TEST_HOST=localhost\nTEST_DOUBLE=  \"quoted\" # comment\nTEST_SINGLE=\t'$TEST_HOST'\n"
+
+	loader := dotenv.Loader{
+		Filenames: []string{writeEnvFile(t, ".env", content)},
+		Expand:    true,
+	}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]string{
+		"TEST_DOUBLE": "quoted",
+		"TEST_SINGLE": "$TEST_HOST",
+	}
+
+	for key, expected := range tests {
+		if got := vars[key]; got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+func TestLoaderValidateNames(t *testing.T) {
+	content := "TEST_VALID=1\nMY-KEY=2\n"
+	path := writeEnvFile(t, ".env", content)
+
+	t.Run("permissive by default", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["MY-KEY"] != "2" {
+			t.Errorf("MY-KEY: expected %q, got %q", "2", vars["MY-KEY"])
+		}
+	})
+
+	t.Run("rejects invalid names", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}, ValidateNames: true}
+
+		_, err := loader.Parse()
+
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected ParseError, got %v", err)
+		}
+
+		if parseErr.File != path || parseErr.Line != 2 {
+			t.Errorf("expected %s:2, got %s:%d", path, parseErr.File, parseErr.Line)
+		}
+	})
+}
+
+func TestEffective(t *testing.T) {
+	t.Setenv("TEST_EFFECTIVE_SHARED", "from-env")
+	os.Unsetenv("TEST_EFFECTIVE_FILE")
+
+	path := writeEnvFile(t, ".env", "TEST_EFFECTIVE_SHARED=from-file\nTEST_EFFECTIVE_FILE=from-file\n")
+
+	effective := dotenv.Effective(path)
+
+	tests := map[string]string{
+		"TEST_EFFECTIVE_SHARED": "from-env",
+		"TEST_EFFECTIVE_FILE":   "from-file",
+	}
+
+	for key, expected := range tests {
+		if got := effective[key]; got != expected {
+			t.Errorf("%s: expected %q, got %q", key, expected, got)
+		}
+	}
+
+	if _, ok := os.LookupEnv("TEST_EFFECTIVE_FILE"); ok {
+		t.Error("Effective should not set the process environment")
+	}
+
+	t.Run("skips unreadable files one by one", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.env")
+
+		effective := dotenv.Effective(missing, path)
+		if got := effective["TEST_EFFECTIVE_FILE"]; got != "from-file" {
+			t.Errorf("expected the readable file to apply, got %q", got)
+		}
+	})
+}
+
+func TestLoaderCollapseWhitespace(t *testing.T) {
+	content := "TEST_UNQUOTED=a    b \t  c\nTEST_QUOTED=\"a    b\"\n"
+	path := writeEnvFile(t, ".env", content)
+
+	t.Run("enabled", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}, CollapseWhitespace: true}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := vars["TEST_UNQUOTED"]; got != "a b c" {
+			t.Errorf("TEST_UNQUOTED: expected %q, got %q", "a b c", got)
+		}
+
+		if got := vars["TEST_QUOTED"]; got != "a    b" {
+			t.Errorf("TEST_QUOTED: expected %q, got %q", "a    b", got)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := vars["TEST_UNQUOTED"]; got != "a    b \t  c" {
+			t.Errorf("TEST_UNQUOTED: expected %q, got %q", "a    b \t  c", got)
+		}
+	})
+}
+
+func TestLoaderNestedAssignments(t *testing.T) {
+	content := `TEST_DB_OPTS=sslmode=require connect_timeout=5
+TEST_DB_OPTS_COMMENT=sslmode=require connect_timeout=5 # postgres options
+TEST_ANCHOR=https://example.com/page#section
+TEST_COLOR=#ff0000
+TEST_EMPTY= # nothing here
+`
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dotenv.Entry{
+		{Key: "TEST_DB_OPTS", Value: "sslmode=require connect_timeout=5"},
+		{Key: "TEST_DB_OPTS_COMMENT", Value: "sslmode=require connect_timeout=5", Comment: "postgres options"},
+		{Key: "TEST_ANCHOR", Value: "https://example.com/page#section"},
+		{Key: "TEST_COLOR", Value: "#ff0000"},
+		{Key: "TEST_EMPTY", Value: "", Comment: "nothing here"},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, entry := range entries {
+		if entry.Key != expected[i].Key || entry.Value != expected[i].Value || entry.Comment != expected[i].Comment {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entry)
+		}
+	}
+}
+
+func TestLoaderOnSet(t *testing.T) {
+	t.Setenv("TEST_ONSET_SHARED", "")
+	t.Setenv("TEST_ONSET_BASE", "")
+
+	base := writeEnvFile(t, ".env", "TEST_ONSET_SHARED=base\nTEST_ONSET_BASE=kept\n")
+	local := writeEnvFile(t, ".env.local", "TEST_ONSET_SHARED=local\n")
+
+	type call struct{ value, file string }
+	calls := make(map[string]call)
+
+	loader := dotenv.Loader{
+		Filenames: []string{base, local},
+		OnSet: func(key, value, file string) {
+			calls[key] = call{value, file}
+		},
+	}
+
+	if err := loader.Collect(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]call{
+		"TEST_ONSET_SHARED": {"local", local},
+		"TEST_ONSET_BASE":   {"kept", base},
+	}
+
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestLoaderQuotedEscapes(t *testing.T) {
+	content := `TEST_BACKSLASH="a\\"
+TEST_QUOTE="a\""
+TEST_MULTILINE="path\"
+more"
+TEST_AFTER=after
+`
+
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		key, value string
+		line       int
+	}{
+		{"TEST_BACKSLASH", `a\`, 1},
+		{"TEST_QUOTE", `a"`, 2},
+		{"TEST_MULTILINE", "path\"\nmore", 3},
+		{"TEST_AFTER", "after", 5},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, want := range expected {
+		got := entries[i]
+		if got.Key != want.key || got.Value != want.value || got.Line != want.line {
+			t.Errorf("entry %d: expected %s=%q at line %d, got %s=%q at line %d",
+				i, want.key, want.value, want.line, got.Key, got.Value, got.Line)
+		}
+	}
+}
+
+func TestLoaderUnclosedQuote(t *testing.T) {
+	content := "TEST_A=\"oops\nTEST_B=1\nTEST_C=\"hello\"\nTEST_D=2\n"
+	loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"TEST_A": `"oops`, "TEST_B": "1", "TEST_C": "hello", "TEST_D": "2"}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("expected %q, got %q", expected, vars)
+	}
+
+	t.Run("long file", func(t *testing.T) {
+		content := "TEST_A=\"open\n" + strings.Repeat("TEST_FILLER=some filler value\n", 20000)
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_A"] != `"open` || vars["TEST_FILLER"] != "some filler value" {
+			t.Errorf("expected the unclosed line to be read alone, got TEST_A=%q", vars["TEST_A"])
+		}
+	})
+}
+
+func TestLoaderValidate(t *testing.T) {
+	good := writeEnvFile(t, ".env", "TEST_VALID_HOST=localhost\nTEST_VALID_URL=\"http://${TEST_VALID_HOST}\"\n")
+	bad := writeEnvFile(t, ".env.local", "TEST_VALID_PORT=8080\nnot an assignment\nTEST_VALID_NAME=\"open\n")
+
+	t.Run("well-formed file", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{good}, Expand: true}
+		if err := loader.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		os.Unsetenv("TEST_VALID_PORT")
+
+		loader := dotenv.Loader{Filenames: []string{good, bad}, Expand: true}
+		err := loader.Validate()
+		if err == nil {
+			t.Fatal("expected validation error, got nil")
+		}
+
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected a ParseError, got %T: %v", err, err)
+		}
+
+		if parseErr.File != bad || parseErr.Line != 2 {
+			t.Errorf("expected error at %s:2, got %s:%d", bad, parseErr.File, parseErr.Line)
+		}
+
+		if _, set := os.LookupEnv("TEST_VALID_PORT"); set {
+			t.Error("expected Validate not to modify the environment")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{good, "does-not-exist.env"}}
+		if err := loader.Validate(); err == nil {
+			t.Fatal("expected error for missing file, got nil")
+		}
+	})
+
+	t.Run("blank lines and indented comments", func(t *testing.T) {
+		tests := map[string]string{
+			"whitespace-only line": "TEST_VALID_HOST=localhost\n  \t\nTEST_VALID_PORT=8080\n",
+			"CRLF blank line":      "TEST_VALID_HOST=localhost\r\n\r\nTEST_VALID_PORT=8080\r\n",
+			"indented comment":     "TEST_VALID_HOST=localhost\n  # note\nTEST_VALID_PORT=8080\n",
+		}
+
+		for name, content := range tests {
+			t.Run(name, func(t *testing.T) {
+				loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}, Strict: true}
+				if err := loader.Validate(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				vars, err := loader.Parse()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if vars["TEST_VALID_HOST"] != "localhost" || vars["TEST_VALID_PORT"] != "8080" {
+					t.Errorf("unexpected variables: %q", vars)
+				}
+			})
+		}
+	})
+}
+
+func TestLoaderLintQuotes(t *testing.T) {
+	t.Run("balanced values", func(t *testing.T) {
+		path := writeEnvFile(t, ".env", "TEST_LINT_A='single'\nTEST_LINT_B=\"it's fine\"\nTEST_LINT_C='say \"hi\"'\nTEST_LINT_D=plain # note\n")
+
+		loader := dotenv.Loader{Filenames: []string{path}, LintQuotes: true}
+		if err := loader.Validate(); err != nil {
+			t.Fatalf("unexpected warning: %v", err)
+		}
+	})
+
+	t.Run("misquoted values", func(t *testing.T) {
+		path := writeEnvFile(t, ".env", "TEST_LINT_OK=ok\nTEST_LINT_A='single\"double'\nTEST_LINT_B='open\nTEST_LINT_C=value\"\n")
+
+		loader := dotenv.Loader{Filenames: []string{path}, LintQuotes: true}
+		err := loader.Validate()
+		if err == nil {
+			t.Fatal("expected warnings, got nil")
+		}
+
+		var lines []int
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var parseErr *dotenv.ParseError
+			if !errors.As(err, &parseErr) || parseErr.File != path {
+				t.Fatalf("expected a ParseError for %s, got %v", path, err)
+			}
+			lines = append(lines, parseErr.Line)
+		}
+
+		if expected := []int{2, 3, 4}; !reflect.DeepEqual(lines, expected) {
+			t.Errorf("expected warnings on lines %v, got %v", expected, lines)
+		}
+
+		if _, err := loader.Parse(); err != nil {
+			t.Errorf("expected misquoted values to load, got %v", err)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		path := writeEnvFile(t, ".env", "TEST_LINT_A='single\"double'\n")
+
+		loader := dotenv.Loader{Filenames: []string{path}}
+		if err := loader.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestLoaderSetenv(t *testing.T) {
+	os.Unsetenv("TEST_SETENV_HOST")
+	os.Unsetenv("TEST_SETENV_PORT")
+
+	path := writeEnvFile(t, ".env", "TEST_SETENV_HOST=localhost\nTEST_SETENV_PORT=8080\n")
+
+	captured := make(map[string]string)
+	loader := dotenv.Loader{
+		Filenames: []string{path},
+		Setenv: func(key, value string) error {
+			captured[key] = value
+			return nil
+		},
+	}
+
+	if err := loader.Collect(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"TEST_SETENV_HOST": "localhost",
+		"TEST_SETENV_PORT": "8080",
+	}
+
+	if !reflect.DeepEqual(captured, expected) {
+		t.Errorf("expected %v, got %v", expected, captured)
+	}
+
+	for key := range expected {
+		if _, set := os.LookupEnv(key); set {
+			t.Errorf("%s: expected process environment to be untouched", key)
+		}
+	}
+
+	t.Run("setter error is returned", func(t *testing.T) {
+		loader.Setenv = func(key, value string) error {
+			return errors.New("sink unavailable")
+		}
+
+		if err := loader.Collect(); err == nil {
+			t.Fatal("expected setter error, got nil")
+		}
+	})
+}
+
+func TestLoaderAllowFileRefs(t *testing.T) {
+	cert := writeEnvFile(t, "cert.pem", "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")
+	content := "TEST_CERT=@" + cert + "\nTEST_HANDLE=\"@someone\"\n"
+
+	t.Run("reads referenced file", func(t *testing.T) {
+		loader := dotenv.Loader{
+			Filenames:     []string{writeEnvFile(t, ".env", content)},
+			AllowFileRefs: true,
+		}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----"; vars["TEST_CERT"] != expected {
+			t.Errorf("TEST_CERT: expected %q, got %q", expected, vars["TEST_CERT"])
+		}
+
+		if vars["TEST_HANDLE"] != "@someone" {
+			t.Errorf("TEST_HANDLE: expected quoted value kept, got %q", vars["TEST_HANDLE"])
+		}
+	})
+
+	t.Run("strips a single trailing newline", func(t *testing.T) {
+		tests := map[string]string{
+			"secret\n":     "secret",
+			"secret\r\n":   "secret",
+			"secret\n\n":   "secret\n",
+			"  a  b \t\n":  "  a  b \t",
+			"no newline\r": "no newline\r",
+		}
+
+		for body, expected := range tests {
+			ref := writeEnvFile(t, "secret.txt", body)
+			loader := dotenv.Loader{
+				Filenames:     []string{writeEnvFile(t, ".env", "TEST_SECRET=@"+ref+"\n")},
+				AllowFileRefs: true,
+			}
+
+			vars, err := loader.Parse()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if vars["TEST_SECRET"] != expected {
+				t.Errorf("file %q: expected %q, got %q", body, expected, vars["TEST_SECRET"])
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_CERT"] != "@"+cert {
+			t.Errorf("expected literal reference, got %q", vars["TEST_CERT"])
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		loader := dotenv.Loader{
+			Filenames:     []string{writeEnvFile(t, ".env", "TEST_CERT=@/does/not/exist.pem\n")},
+			AllowFileRefs: true,
+		}
+
+		var parseErr *dotenv.ParseError
+		if _, err := loader.Parse(); !errors.As(err, &parseErr) || parseErr.Line != 1 {
+			t.Fatalf("expected ParseError at line 1, got %v", err)
+		}
+	})
+}
+
+func TestLoaderSecretResolvers(t *testing.T) {
+	secrets := map[string]string{"fake://db/password": "hunter2", "fake://special/db": "special"}
+	fake := func(ref string) (string, error) {
+		secret, ok := secrets[ref]
+		if !ok {
+			return "", fmt.Errorf("secret %s not found", ref)
+		}
+		return secret, nil
+	}
+	special := func(ref string) (string, error) {
+		return "from special resolver", nil
+	}
+
+	content := "TEST_SECRET_PASS=fake://db/password\nTEST_SECRET_QUOTED=\"fake://db/password\"\nTEST_SECRET_PLAIN=value\nTEST_SECRET_SPECIAL=fake://special/db\n"
+
+	t.Run("resolves matching schemes", func(t *testing.T) {
+		loader := dotenv.Loader{
+			Filenames: []string{writeEnvFile(t, ".env", content)},
+			SecretResolvers: map[string]dotenv.SecretResolver{
+				"fake://":         fake,
+				"fake://special/": special,
+			},
+		}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := map[string]string{
+			"TEST_SECRET_PASS":    "hunter2",
+			"TEST_SECRET_QUOTED":  "fake://db/password",
+			"TEST_SECRET_PLAIN":   "value",
+			"TEST_SECRET_SPECIAL": "from special resolver",
+		}
+		if !reflect.DeepEqual(vars, expected) {
+			t.Errorf("expected %v, got %v", expected, vars)
+		}
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		loader := dotenv.Loader{
+			Filenames:       []string{writeEnvFile(t, ".env", "TEST_SECRET_PLAIN=value\nTEST_SECRET_PASS=fake://missing\n")},
+			SecretResolvers: map[string]dotenv.SecretResolver{"fake://": fake},
+		}
+
+		_, err := loader.Parse()
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != 2 || !strings.Contains(err.Error(), "secret fake://missing not found") {
+			t.Fatalf("expected resolver error at line 2, got %v", err)
+		}
+	})
+}
+
+func TestLoaderMaxValueLen(t *testing.T) {
+	content := "TEST_SHORT=abc\nTEST_BLOB=" + strings.Repeat("x", 17) + "\n"
+	path := writeEnvFile(t, ".env", content)
+
+	t.Run("value over the limit", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}, MaxValueLen: 16}
+
+		_, err := loader.Parse()
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected ParseError, got %v", err)
+		}
+
+		if parseErr.Line != 2 || !strings.Contains(err.Error(), "TEST_BLOB") {
+			t.Errorf("expected error naming TEST_BLOB at line 2, got %v", err)
+		}
+	})
+
+	t.Run("value within the limit", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}, MaxValueLen: 17}
+		if _, err := loader.Parse(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("zero means unlimited", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{path}}
+		if _, err := loader.Parse(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestLoaderStrictTrailingText(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		value   string
+		strict  bool
+	}{
+		"trailing text":           {`TEST_TRAILING="value"extra`, `"value"extra`, false},
+		"second quoted string":    {`TEST_TRAILING="value" "more"`, `"value" "more"`, false},
+		"single quoted trailing":  {`TEST_TRAILING='value' more`, `'value' more`, false},
+		"mismatched quotes":       {`TEST_TRAILING="mixed'`, `"mixed'`, false},
+		"inline comment":          {`TEST_TRAILING="value" # comment`, "value", true},
+		"comment without a space": {`TEST_TRAILING="value"# comment`, "value", true},
+		"trailing whitespace":     {"TEST_TRAILING=\"value\"  \t", "value", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeEnvFile(t, ".env", tt.content+"\n")
+
+			lenient := dotenv.Loader{Filenames: []string{path}}
+			vars, err := lenient.Parse()
+			if err != nil {
+				t.Fatalf("lenient: unexpected error: %v", err)
+			}
+
+			if vars["TEST_TRAILING"] != tt.value {
+				t.Errorf("lenient: expected %q, got %q", tt.value, vars["TEST_TRAILING"])
+			}
+
+			strict := dotenv.Loader{Filenames: []string{path}, Strict: true}
+			_, err = strict.Parse()
+			if tt.strict && err != nil {
+				t.Errorf("strict: unexpected error: %v", err)
+			}
+
+			if !tt.strict && err == nil {
+				t.Error("strict: expected error for trailing text, got nil")
+			}
+		})
+	}
+}
+
+func TestLoaderEmptyKey(t *testing.T) {
+	for _, content := range []string{"=value", `""=value`, "export =value"} {
+		t.Run(content, func(t *testing.T) {
+			path := writeEnvFile(t, ".env", "TEST_EMPTY_KEY_BEFORE=1\n"+content+"\nTEST_EMPTY_KEY_AFTER=2\n")
+
+			lenient := dotenv.Loader{Filenames: []string{path}}
+			vars, err := lenient.Parse()
+			if err != nil {
+				t.Fatalf("lenient: unexpected error: %v", err)
+			}
+
+			if _, ok := vars[""]; ok {
+				t.Error("lenient: expected the empty key to be skipped")
+			}
+
+			if vars["TEST_EMPTY_KEY_BEFORE"] != "1" || vars["TEST_EMPTY_KEY_AFTER"] != "2" {
+				t.Errorf("lenient: expected surrounding lines to load, got %v", vars)
+			}
+
+			strict := dotenv.Loader{Filenames: []string{path}, Strict: true}
+			_, err = strict.Parse()
+
+			var parseErr *dotenv.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Line != 2 || !strings.Contains(err.Error(), "empty key") {
+				t.Errorf("strict: expected empty key error at line 2, got %v", err)
+			}
+		})
+	}
+
+	t.Run("collect does not fail", func(t *testing.T) {
+		t.Setenv("TEST_EMPTY_KEY_AFTER", "")
+
+		path := writeEnvFile(t, ".env", "=value\nTEST_EMPTY_KEY_AFTER=2\n")
+		loader := dotenv.Loader{Filenames: []string{path}}
+		if err := loader.Collect(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := os.Getenv("TEST_EMPTY_KEY_AFTER"); got != "2" {
+			t.Errorf("expected %q, got %q", "2", got)
+		}
+	})
+}
+
+func TestLoaderDetectCaseCollisions(t *testing.T) {
+	t.Run("within a file", func(t *testing.T) {
+		path := writeEnvFile(t, ".env", "TEST_CASE_OTHER=1\nTest_Case_Foo=a\nTEST_CASE_FOO=b\n")
+
+		strict := dotenv.Loader{Filenames: []string{path}, Strict: true, DetectCaseCollisions: true}
+		_, err := strict.Parse()
+
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != 3 {
+			t.Fatalf("expected ParseError at line 3, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "Test_Case_Foo") {
+			t.Errorf("expected the earlier key to be named, got %v", err)
+		}
+
+		if err := strict.Validate(); err == nil {
+			t.Error("expected Validate to report the collision")
+		}
+	})
+
+	t.Run("with the environment", func(t *testing.T) {
+		t.Setenv("TEST_CASE_PATH", "/bin")
+		path := writeEnvFile(t, ".env", "Test_Case_Path=/usr/bin\nTEST_CASE_PATH=/sbin\n")
+
+		strict := dotenv.Loader{Filenames: []string{path}, Strict: true, DetectCaseCollisions: true}
+		var parseErr *dotenv.ParseError
+		if _, err := strict.Parse(); !errors.As(err, &parseErr) || parseErr.Line != 1 {
+			t.Fatalf("expected ParseError at line 1, got %v", err)
+		}
+	})
+
+	t.Run("off by default and without strict", func(t *testing.T) {
+		path := writeEnvFile(t, ".env", "Test_Case_Foo=a\nTEST_CASE_FOO=b\n")
+
+		for _, loader := range []dotenv.Loader{
+			{Filenames: []string{path}, Strict: true},
+			{Filenames: []string{path}, DetectCaseCollisions: true},
+		} {
+			vars, err := loader.Parse()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if vars["Test_Case_Foo"] != "a" || vars["TEST_CASE_FOO"] != "b" {
+				t.Errorf("expected both keys, got %v", vars)
+			}
+		}
+	})
+}
+
+func TestLoaderUnicodeEscapes(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		value   string
+		strict  bool
+	}{
+		"unicode escape":    {`TEST_ESCAPE="\u00e9"`, "é", true},
+		"hex escape":        {`TEST_ESCAPE="caf\xe9"`, "café", true},
+		"surrogate pair":    {`TEST_ESCAPE="\ud83d\ude00"`, "\U0001F600", true},
+		"escaped backslash": {`TEST_ESCAPE="\\u00e9"`, `\u00e9`, true},
+		"single quoted":     {`TEST_ESCAPE='\u00e9'`, `\u00e9`, true},
+		"short escape":      {`TEST_ESCAPE="\u00e"`, `\u00e`, false},
+		"not hex":           {`TEST_ESCAPE="\xzz"`, `\xzz`, false},
+		"lone surrogate":    {`TEST_ESCAPE="\ud83d!"`, `\ud83d!`, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeEnvFile(t, ".env", tt.content+"\n")
+
+			lenient := dotenv.Loader{Filenames: []string{path}}
+			vars, err := lenient.Parse()
+			if err != nil {
+				t.Fatalf("lenient: unexpected error: %v", err)
+			}
+
+			if vars["TEST_ESCAPE"] != tt.value {
+				t.Errorf("lenient: expected %q, got %q", tt.value, vars["TEST_ESCAPE"])
+			}
+
+			strict := dotenv.Loader{Filenames: []string{path}, Strict: true}
+			_, err = strict.Parse()
+			if tt.strict && err != nil {
+				t.Errorf("strict: unexpected error: %v", err)
+			}
+
+			var parseErr *dotenv.ParseError
+			if !tt.strict && !errors.As(err, &parseErr) {
+				t.Errorf("strict: expected ParseError for invalid escape, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadStdin(t *testing.T) {
+	os.Unsetenv("TEST_STDIN_HOST")
+	t.Cleanup(func() { os.Unsetenv("TEST_STDIN_HOST") })
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = stdin })
+
+	if _, err := w.WriteString("TEST_STDIN_HOST=piped\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Close()
+
+	if err := dotenv.Load("-"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := os.Getenv("TEST_STDIN_HOST"); got != "piped" {
+		t.Errorf("expected %q, got %q", "piped", got)
+	}
+}
+
+func TestLoaderDirective(t *testing.T) {
+	t.Run("strict rejects duplicate key", func(t *testing.T) {
+		content := "#!dotenv v1 strict\nTEST_DIRECTIVE=first\nTEST_DIRECTIVE=second\n"
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+		_, err := loader.Parse()
+		var parseErr *dotenv.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != 3 {
+			t.Fatalf("expected ParseError at line 3, got %v", err)
+		}
+	})
+
+	t.Run("without directive last assignment wins", func(t *testing.T) {
+		content := "TEST_DIRECTIVE=first\nTEST_DIRECTIVE=second\n"
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_DIRECTIVE"] != "second" {
+			t.Errorf("expected %q, got %q", "second", vars["TEST_DIRECTIVE"])
+		}
+	})
+
+	t.Run("expand", func(t *testing.T) {
+		content := "#!dotenv expand\nTEST_DIRECTIVE_HOST=localhost\nTEST_DIRECTIVE_URL=http://${TEST_DIRECTIVE_HOST}\n"
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+		vars, err := loader.Parse()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if vars["TEST_DIRECTIVE_URL"] != "http://localhost" {
+			t.Errorf("expected expanded URL, got %q", vars["TEST_DIRECTIVE_URL"])
+		}
+	})
+
+	t.Run("only the first line is a directive", func(t *testing.T) {
+		content := "TEST_DIRECTIVE=first\n#!dotenv strict\nTEST_DIRECTIVE=second\n"
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", content)}}
+
+		if _, err := loader.Parse(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		loader := dotenv.Loader{Filenames: []string{writeEnvFile(t, ".env", "#!dotenv v1 turbo\n")}}
+
+		if _, err := loader.Parse(); err == nil {
+			t.Fatal("expected error for unknown option, got nil")
+		}
+	})
+}
+
+func TestMarshalEntries(t *testing.T) {
+	base := writeEnvFile(t, ".env", "TEST_HOST=localhost\nTEST_PORT=8080\nTEST_NAME=base app\n")
+	local := writeEnvFile(t, ".env.local", "TEST_PORT=9090\nTEST_DEBUG=true\n")
+
+	loader := dotenv.Loader{Filenames: []string{base, local}}
+	entries, err := loader.ParseWithSource()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := dotenv.MarshalEntries(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# from " + base + "\n" +
+		"TEST_HOST=localhost\n" +
+		"TEST_NAME=\"base app\"\n" +
+		"\n" +
+		"# from " + local + "\n" +
+		"TEST_PORT=9090\n" +
+		"TEST_DEBUG=true\n"
+
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
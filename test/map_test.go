@@ -0,0 +1,105 @@
+package dotenv_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type Region string
+
+func (r *Region) UnmarshalText(text []byte) error {
+	switch value := Region(text); value {
+	case "US", "EU":
+		*r = value
+		return nil
+	default:
+		return fmt.Errorf("unknown region %q", value)
+	}
+}
+
+func TestUnmarshalPrefixMap(t *testing.T) {
+	t.Run("string keys", func(t *testing.T) {
+		t.Setenv("TEST_LABEL_APP", "api")
+		t.Setenv("TEST_LABEL_TEAM", "core")
+
+		var cfg struct {
+			Labels map[string]string `envPrefix:"TEST_LABEL_"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(cfg.Labels) != 2 || cfg.Labels["APP"] != "api" || cfg.Labels["TEAM"] != "core" {
+			t.Errorf("unexpected labels: %v", cfg.Labels)
+		}
+	})
+
+	t.Run("enum keys", func(t *testing.T) {
+		t.Setenv("TEST_LIMIT_US", "100")
+		t.Setenv("TEST_LIMIT_EU", "200")
+
+		var cfg struct {
+			Limits map[Region]int `envPrefix:"TEST_LIMIT_"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Limits["US"] != 100 || cfg.Limits["EU"] != 200 {
+			t.Errorf("unexpected limits: %v", cfg.Limits)
+		}
+	})
+
+	t.Run("invalid enum key returns error", func(t *testing.T) {
+		t.Setenv("TEST_QUOTA_APAC", "300")
+
+		var cfg struct {
+			Quotas map[Region]int `envPrefix:"TEST_QUOTA_"`
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected error for unknown region, got nil")
+		}
+	})
+}
+
+func TestMarshalPrefixMap(t *testing.T) {
+	type LabelConfig struct {
+		Name   string            `env:"TEST_APP_NAME"`
+		Labels map[string]string `envPrefix:"TEST_LABEL_"`
+	}
+
+	cfg := LabelConfig{
+		Name: "api",
+		Labels: map[string]string{
+			"TEAM": "core team",
+			"APP":  "api",
+		},
+	}
+
+	data, err := dotenv.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "TEST_APP_NAME=api\nTEST_LABEL_APP=api\nTEST_LABEL_TEAM=\"core team\"\n"
+	if got := string(data); got != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	path := writeEnvFile(t, ".env", string(data))
+
+	var decoded LabelConfig
+	if err := dotenv.Decode(&decoded, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, cfg) {
+		t.Errorf("round trip: expected %+v, got %+v", cfg, decoded)
+	}
+}
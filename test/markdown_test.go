@@ -0,0 +1,50 @@
+package dotenv_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type ConfigDocumented struct {
+	Host   string            `env:"DOC_HOST" required:"true" comment:"Database host"`
+	Port   int               `env:"DOC_PORT" default:"5432" comment:"Database port"`
+	Mode   string            `env:"DOC_MODE" default:"a|b" comment:"Either a | b"`
+	Labels map[string]string `envPrefix:"DOC_LABEL_"`
+}
+
+func TestMarshalMarkdown(t *testing.T) {
+	data, err := dotenv.MarshalMarkdown(&ConfigDocumented{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "| Key | Type | Default | Required | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `DOC_HOST` | `string` |  | yes | Database host |\n" +
+		"| `DOC_PORT` | `int` | `5432` | no | Database port |\n" +
+		"| `DOC_MODE` | `string` | `a\\|b` | no | Either a \\| b |\n" +
+		"| `DOC_LABEL_*` | `map[string]string` |  | no |  |\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	t.Run("one row per field", func(t *testing.T) {
+		data, err := dotenv.MarshalMarkdown(ConfigTest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rows := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(rows) != 2+len(dotenv.Keys(ConfigTest{})) {
+			t.Errorf("expected a row per key, got:\n%s", data)
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := dotenv.MarshalMarkdown(42); err == nil {
+			t.Error("expected error for non-struct value")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package dotenv_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type MergeConfig struct {
+	Host    string  `json:"host" env:"TEST_MERGE_HOST" required:"true"`
+	Port    int     `json:"port" env:"TEST_MERGE_PORT" default:"8080"`
+	Debug   bool    `json:"debug" env:"TEST_MERGE_DEBUG"`
+	Name    *string `json:"name" env:"TEST_MERGE_NAME"`
+	Timeout int     `json:"timeout" env:"TEST_MERGE_TIMEOUT" default:"30"`
+}
+
+func TestMergeEnv(t *testing.T) {
+	base := `{"host": "db.internal", "port": 5432, "debug": true, "name": "base"}`
+
+	var cfg MergeConfig
+	if err := json.Unmarshal([]byte(base), &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Unsetenv("TEST_MERGE_HOST")
+	os.Unsetenv("TEST_MERGE_TIMEOUT")
+	t.Setenv("TEST_MERGE_PORT", "6543")
+	t.Setenv("TEST_MERGE_DEBUG", "")
+	t.Setenv("TEST_MERGE_NAME", "")
+
+	if err := dotenv.MergeEnv(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Host != "db.internal" {
+		t.Errorf("Host: expected base value kept, got %q", cfg.Host)
+	}
+
+	if cfg.Port != 6543 {
+		t.Errorf("Port: expected env value 6543, got %d", cfg.Port)
+	}
+
+	if !cfg.Debug {
+		t.Error("Debug: expected empty variable not to zero the field")
+	}
+
+	if cfg.Name == nil || *cfg.Name != "base" {
+		t.Errorf("Name: expected base value kept, got %v", cfg.Name)
+	}
+
+	if cfg.Timeout != 30 {
+		t.Errorf("Timeout: expected default for zero field, got %d", cfg.Timeout)
+	}
+
+	t.Run("required field still zero", func(t *testing.T) {
+		var empty MergeConfig
+		if err := dotenv.MergeEnv(&empty); err == nil {
+			t.Fatal("expected required error, got nil")
+		}
+	})
+}
+
+func TestUnmarshalWithDefaults(t *testing.T) {
+	name := "defaults"
+	defaults := MergeConfig{Host: "localhost", Port: 5432, Debug: true, Name: &name}
+
+	os.Unsetenv("TEST_MERGE_HOST")
+	os.Unsetenv("TEST_MERGE_DEBUG")
+	os.Unsetenv("TEST_MERGE_NAME")
+	t.Setenv("TEST_MERGE_PORT", "6543")
+	t.Setenv("TEST_MERGE_TIMEOUT", "5")
+
+	cfg := MergeConfig{Host: "overwritten by defaults"}
+	if err := dotenv.UnmarshalWithDefaults(&cfg, defaults); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Host != "localhost" || !cfg.Debug || cfg.Name == nil || *cfg.Name != "defaults" {
+		t.Errorf("expected defaults to fill Host, Debug and Name, got %+v", cfg)
+	}
+
+	if cfg.Port != 6543 || cfg.Timeout != 5 {
+		t.Errorf("expected env to override Port and Timeout, got Port %d, Timeout %d", cfg.Port, cfg.Timeout)
+	}
+
+	t.Run("defaults of another type", func(t *testing.T) {
+		partial := struct {
+			Host string
+			Port string
+		}{Host: "db.internal", Port: "not an int"}
+
+		var cfg MergeConfig
+		if err := dotenv.UnmarshalWithDefaults(&cfg, &partial); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Host != "db.internal" {
+			t.Errorf("expected matching field to be copied, got %q", cfg.Host)
+		}
+	})
+
+	t.Run("invalid defaults", func(t *testing.T) {
+		var cfg MergeConfig
+		if err := dotenv.UnmarshalWithDefaults(&cfg, "invalid"); err == nil {
+			t.Fatal("expected error for non-struct defaults, got nil")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package dotenv_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type DatabaseConfig struct {
+	Host string `env:"TEST_NESTED_DB_HOST"`
+	Port int    `env:"TEST_NESTED_DB_PORT" default:"5432"`
+}
+
+type CacheConfig struct {
+	URL string `env:"TEST_NESTED_CACHE_URL"`
+}
+
+type CommonConfig struct {
+	Env string `env:"TEST_NESTED_ENV"`
+}
+
+type NestedConfig struct {
+	CommonConfig
+	Name     string `env:"TEST_NESTED_NAME"`
+	Database DatabaseConfig
+	Cache    CacheConfig `envSection:"Redis"`
+	Debug    bool        `env:"TEST_NESTED_DEBUG"`
+}
+
+func TestMarshalSections(t *testing.T) {
+	cfg := NestedConfig{
+		CommonConfig: CommonConfig{Env: "prod"},
+		Name:         "api",
+		Database:     DatabaseConfig{Host: "db.internal", Port: 5432},
+		Cache:        CacheConfig{URL: "redis://cache"},
+		Debug:        true,
+	}
+
+	data, err := dotenv.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "TEST_NESTED_ENV=prod\n" +
+		"TEST_NESTED_NAME=api\n" +
+		"\n" +
+		"# [Database]\n" +
+		"TEST_NESTED_DB_HOST=db.internal\n" +
+		"TEST_NESTED_DB_PORT=5432\n" +
+		"\n" +
+		"# [Redis]\n" +
+		"TEST_NESTED_CACHE_URL=redis://cache\n" +
+		"\n" +
+		"TEST_NESTED_DEBUG=true\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestUnmarshalNested(t *testing.T) {
+	t.Setenv("TEST_NESTED_ENV", "staging")
+	t.Setenv("TEST_NESTED_DB_HOST", "db.internal")
+	t.Setenv("TEST_NESTED_CACHE_URL", "redis://cache")
+
+	var cfg NestedConfig
+	if err := dotenv.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Env != "staging" || cfg.Database.Host != "db.internal" || cfg.Database.Port != 5432 || cfg.Cache.URL != "redis://cache" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Run("keys include nested fields", func(t *testing.T) {
+		expected := []string{
+			"TEST_NESTED_ENV", "TEST_NESTED_NAME", "TEST_NESTED_DB_HOST", "TEST_NESTED_DB_PORT",
+			"TEST_NESTED_CACHE_URL", "TEST_NESTED_DEBUG",
+		}
+		if got := dotenv.Keys(cfg); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("errors name the nested field", func(t *testing.T) {
+		t.Setenv("TEST_NESTED_DB_PORT", "not-a-port")
+
+		err := dotenv.Unmarshal(&cfg)
+		if err == nil || !strings.HasPrefix(err.Error(), "error setting field Database.Port") {
+			t.Errorf("expected error for Database.Port, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package dotenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type LevelConfig struct {
+	Level string `env:"TEST_LEVEL" oneof:"debug|info|warn|error" insensitive:"true" default:"info"`
+	Mode  string `env:"TEST_MODE" oneof:"dev|prod"`
+}
+
+func TestUnmarshalOneOf(t *testing.T) {
+	t.Run("mixed case is normalized", func(t *testing.T) {
+		for _, input := range []string{"Info", "INFO", "info"} {
+			t.Setenv("TEST_LEVEL", input)
+
+			var cfg LevelConfig
+			if err := dotenv.Unmarshal(&cfg); err != nil {
+				t.Fatalf("unexpected error for %q: %v", input, err)
+			}
+
+			if cfg.Level != "info" {
+				t.Errorf("expected Level info for %q, got %q", input, cfg.Level)
+			}
+		}
+	})
+
+	t.Run("default is used when unset", func(t *testing.T) {
+		os.Unsetenv("TEST_LEVEL")
+
+		var cfg LevelConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Level != "info" {
+			t.Errorf("expected default info, got %q", cfg.Level)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("TEST_LEVEL", "trace")
+
+		var cfg LevelConfig
+		err := dotenv.Unmarshal(&cfg)
+		if err == nil {
+			t.Fatal("expected error for invalid level, got nil")
+		}
+
+		if !strings.Contains(err.Error(), `"trace" is not one of debug|info|warn|error`) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("case sensitive without insensitive tag", func(t *testing.T) {
+		t.Setenv("TEST_LEVEL", "info")
+		t.Setenv("TEST_MODE", "Prod")
+
+		var cfg LevelConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected error for Prod, got nil")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package dotenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type PointerConfig struct {
+	Timeout *int    `env:"TEST_PTR_TIMEOUT" default:"30"`
+	Name    *string `env:"TEST_PTR_NAME"`
+}
+
+func TestUnmarshalPointer(t *testing.T) {
+	t.Run("allocates pointer when set", func(t *testing.T) {
+		t.Setenv("TEST_PTR_TIMEOUT", "10")
+		t.Setenv("TEST_PTR_NAME", "gopher")
+
+		var cfg PointerConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout == nil || *cfg.Timeout != 10 {
+			t.Errorf("Timeout: expected 10, got %v", cfg.Timeout)
+		}
+
+		if cfg.Name == nil || *cfg.Name != "gopher" {
+			t.Errorf("Name: expected %q, got %v", "gopher", cfg.Name)
+		}
+	})
+
+	t.Run("uses default when absent", func(t *testing.T) {
+		os.Unsetenv("TEST_PTR_TIMEOUT")
+		os.Unsetenv("TEST_PTR_NAME")
+
+		var cfg PointerConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout == nil || *cfg.Timeout != 30 {
+			t.Errorf("Timeout: expected default 30, got %v", cfg.Timeout)
+		}
+
+		if cfg.Name != nil {
+			t.Errorf("Name: expected nil, got %q", *cfg.Name)
+		}
+	})
+
+	t.Run("null overrides default", func(t *testing.T) {
+		t.Setenv("TEST_PTR_TIMEOUT", "null")
+
+		var cfg PointerConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout != nil {
+			t.Errorf("Timeout: expected nil, got %d", *cfg.Timeout)
+		}
+	})
+
+	t.Run("empty overrides default", func(t *testing.T) {
+		t.Setenv("TEST_PTR_TIMEOUT", "")
+
+		timeout := 5
+		cfg := PointerConfig{Timeout: &timeout}
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout != nil {
+			t.Errorf("Timeout: expected nil, got %d", *cfg.Timeout)
+		}
+	})
+
+	t.Run("custom null value", func(t *testing.T) {
+		original := dotenv.NullValue
+		dotenv.NullValue = "none"
+		defer func() {
+			dotenv.NullValue = original
+		}()
+
+		t.Setenv("TEST_PTR_TIMEOUT", "none")
+
+		var cfg PointerConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Timeout != nil {
+			t.Errorf("Timeout: expected nil, got %d", *cfg.Timeout)
+		}
+	})
+}
+
+func TestMarshalPointer(t *testing.T) {
+	timeout := 10
+	cfg := PointerConfig{Timeout: &timeout}
+
+	data, err := dotenv.Marshal(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := string(data)
+	for _, expected := range []string{"TEST_PTR_TIMEOUT=10\n", "TEST_PTR_NAME=\n"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package dotenv_test
+
+import (
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestUnmarshalStripPrefix(t *testing.T) {
+	type PrefixConfig struct {
+		Port   int            `env:"PORT"`
+		Host   string         `env:"HOST"`
+		Limits map[string]int `envPrefix:"LIMIT_"`
+	}
+
+	t.Setenv("MYAPP_PORT", "8080")
+	t.Setenv("PORT", "9090")
+	t.Setenv("HOST", "localhost")
+	t.Setenv("MYAPP_LIMIT_US", "100")
+
+	var cfg PrefixConfig
+	opts := dotenv.UnmarshalOptions{StripPrefix: "MYAPP_"}
+	if err := opts.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Errorf("Port: expected prefixed value 8080, got %d", cfg.Port)
+	}
+
+	if cfg.Host != "localhost" {
+		t.Errorf("Host: expected unprefixed fallback, got %q", cfg.Host)
+	}
+
+	if cfg.Limits["US"] != 100 {
+		t.Errorf("Limits: expected US=100, got %v", cfg.Limits)
+	}
+}
+
+func TestLoaderStripPrefix(t *testing.T) {
+	path := writeEnvFile(t, ".env", "MYAPP_PORT=8080\nHOST=localhost\nMYAPP_=kept\n")
+
+	loader := dotenv.Loader{Filenames: []string{path}, StripPrefix: "MYAPP_"}
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"PORT": "8080", "HOST": "localhost", "MYAPP_": "kept"}
+	for key, value := range expected {
+		if vars[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, vars[key])
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package dotenv_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestLoadWithReport(t *testing.T) {
+	t.Setenv("TEST_REPORT_PORT", "")
+	t.Setenv("TEST_REPORT_HOST", "")
+	t.Setenv("TEST_REPORT_DEBUG", "")
+	os.Unsetenv("TEST_REPORT_PORT")
+	os.Unsetenv("TEST_REPORT_HOST")
+	os.Unsetenv("TEST_REPORT_DEBUG")
+
+	base := writeEnvFile(t, ".env", "TEST_REPORT_PORT=8080\nTEST_REPORT_HOST=localhost\nTEST_REPORT_DEBUG=false\nTEST_REPORT_DEBUG=true\n")
+	local := writeEnvFile(t, ".env.local", "TEST_REPORT_PORT=9090\n")
+
+	report, err := dotenv.LoadWithReport(base, local)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dotenv.Override{
+		{Key: "TEST_REPORT_PORT", File: local, Overridden: []string{base}},
+	}
+
+	if !reflect.DeepEqual(report.Overrides, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report.Overrides)
+	}
+
+	if got := os.Getenv("TEST_REPORT_PORT"); got != "9090" {
+		t.Errorf("TEST_REPORT_PORT: expected %q, got %q", "9090", got)
+	}
+}
@@ -0,0 +1,72 @@
+package dotenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type StrictConfig struct {
+	Host    string `env:"TEST_REQ_HOST"`
+	Port    int    `env:"TEST_REQ_PORT"`
+	Token   string `env:"TEST_REQ_TOKEN"`
+	Region  string `env:"TEST_REQ_REGION" default:"us-east-1"`
+	Comment string `env:"TEST_REQ_COMMENT" optional:"true"`
+}
+
+func TestUnmarshalRequireAll(t *testing.T) {
+	opts := dotenv.UnmarshalOptions{RequireAll: true}
+
+	t.Run("reports every missing field", func(t *testing.T) {
+		t.Setenv("TEST_REQ_HOST", "localhost")
+		os.Unsetenv("TEST_REQ_PORT")
+		os.Unsetenv("TEST_REQ_TOKEN")
+		os.Unsetenv("TEST_REQ_REGION")
+		os.Unsetenv("TEST_REQ_COMMENT")
+
+		var cfg StrictConfig
+		err := opts.Unmarshal(&cfg)
+		if err == nil {
+			t.Fatal("expected missing fields error, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "Port, Token") {
+			t.Errorf("expected Port and Token to be listed, got %v", err)
+		}
+
+		for _, name := range []string{"Host", "Region", "Comment"} {
+			if strings.Contains(err.Error(), name) {
+				t.Errorf("expected %s not to be listed, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("optional and defaulted fields may be absent", func(t *testing.T) {
+		t.Setenv("TEST_REQ_HOST", "localhost")
+		t.Setenv("TEST_REQ_PORT", "8080")
+		t.Setenv("TEST_REQ_TOKEN", "secret")
+		os.Unsetenv("TEST_REQ_REGION")
+		os.Unsetenv("TEST_REQ_COMMENT")
+
+		var cfg StrictConfig
+		if err := opts.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Region != "us-east-1" {
+			t.Errorf("expected default region, got %q", cfg.Region)
+		}
+	})
+
+	t.Run("without RequireAll fields stay optional", func(t *testing.T) {
+		os.Unsetenv("TEST_REQ_PORT")
+		os.Unsetenv("TEST_REQ_TOKEN")
+
+		var cfg StrictConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
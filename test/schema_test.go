@@ -0,0 +1,75 @@
+package dotenv_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Run("matching file", func(t *testing.T) {
+		path := writeEnvFile(t, ".env.example", "TEST_HOST=\nTEST_PORT=\nTEST_DEBUG=\nTEST_RATE=\n")
+
+		if err := dotenv.ValidateSchema(&ConfigTest{}, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		path := writeEnvFile(t, ".env.example", "TEST_HOST=\nTEST_PORT=\nTEST_DEBUG=\n")
+
+		err := dotenv.ValidateSchema(ConfigTest{}, path)
+		if err == nil {
+			t.Fatal("expected schema error, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "missing keys: TEST_RATE") {
+			t.Errorf("expected missing TEST_RATE, got %v", err)
+		}
+	})
+
+	t.Run("extra key", func(t *testing.T) {
+		path := writeEnvFile(t, ".env.example", "TEST_HOST=\nTEST_PORT=\nTEST_DEBUG=\nTEST_RATE=\nTEST_UNUSED=\n")
+
+		err := dotenv.ValidateSchema(&ConfigTest{}, path)
+		if err == nil {
+			t.Fatal("expected schema error, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "extra keys: TEST_UNUSED") {
+			t.Errorf("expected extra TEST_UNUSED, got %v", err)
+		}
+	})
+
+	t.Run("missing file returns error", func(t *testing.T) {
+		if err := dotenv.ValidateSchema(&ConfigTest{}, "does-not-exist.env"); err == nil {
+			t.Fatal("expected error for missing file, got nil")
+		}
+	})
+}
+
+func TestKeys(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		expected := []string{"TEST_HOST", "TEST_PORT", "TEST_DEBUG", "TEST_RATE"}
+
+		if got := dotenv.Keys(ConfigTest{}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("nil struct pointer", func(t *testing.T) {
+		expected := []string{"TEST_AUTH", "TEST_NAME"}
+
+		if got := dotenv.Keys((*ConfigWithDefault)(nil)); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("non struct", func(t *testing.T) {
+		if got := dotenv.Keys("invalid"); got != nil {
+			t.Errorf("expected nil, got %v", got)
+		}
+	})
+}
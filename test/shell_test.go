@@ -0,0 +1,63 @@
+package dotenv_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestMarshalShell(t *testing.T) {
+	type ShellConfig struct {
+		Name  string `env:"TEST_NAME"`
+		Quote string `env:"TEST_QUOTE"`
+		Port  int    `env:"TEST_PORT"`
+	}
+
+	cfg := ShellConfig{Name: "my app $HOME", Quote: `it's C:\tmp`, Port: 8080}
+
+	tests := map[dotenv.Shell]string{
+		dotenv.Bash: "export TEST_NAME='my app $HOME'\n" +
+			"export TEST_QUOTE='it'\\''s C:\\tmp'\n" +
+			"export TEST_PORT='8080'\n",
+		dotenv.Fish: "set -x TEST_NAME 'my app $HOME'\n" +
+			"set -x TEST_QUOTE 'it\\'s C:\\\\tmp'\n" +
+			"set -x TEST_PORT '8080'\n",
+		dotenv.PowerShell: "$env:TEST_NAME = 'my app $HOME'\n" +
+			"$env:TEST_QUOTE = 'it''s C:\\tmp'\n" +
+			"$env:TEST_PORT = '8080'\n",
+	}
+
+	for shell, expected := range tests {
+		t.Run(string(shell), func(t *testing.T) {
+			data, err := dotenv.MarshalShell(&cfg, shell)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(data) != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+			}
+		})
+	}
+
+	t.Run("rejects keys that are not names", func(t *testing.T) {
+		type PrefixConfig struct {
+			Limits map[string]string `envPrefix:"LIMIT_"`
+		}
+
+		cfg := PrefixConfig{Limits: map[string]string{"x=1; echo pwned; Y": "v"}}
+		for _, shell := range []dotenv.Shell{dotenv.Bash, dotenv.Fish, dotenv.PowerShell} {
+			data, err := dotenv.MarshalShell(&cfg, shell)
+			if err == nil || !strings.Contains(err.Error(), "not a valid variable name") {
+				t.Errorf("%s: expected invalid name error, got %v and %q", shell, err, data)
+			}
+		}
+	})
+
+	t.Run("unknown shell", func(t *testing.T) {
+		if _, err := dotenv.MarshalShell(&cfg, "tcsh"); err == nil {
+			t.Fatal("expected error for unknown shell, got nil")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package dotenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type ConfigSource struct {
+	Token  string `env:"TEST_SOURCE_TOKEN" source:"file"`
+	Region string `env:"TEST_SOURCE_REGION" source:"env" default:"local"`
+	Name   string `env:"TEST_SOURCE_NAME"`
+}
+
+func TestUnmarshalSource(t *testing.T) {
+	t.Run("file only", func(t *testing.T) {
+		dotenv.ClearStore()
+		t.Cleanup(dotenv.ClearStore)
+		t.Setenv("TEST_SOURCE_TOKEN", "inherited")
+
+		var cfg ConfigSource
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Token != "" {
+			t.Errorf("expected an inherited variable to be ignored, got %q", cfg.Token)
+		}
+
+		if err := dotenv.Overload(writeEnvFile(t, ".env", "TEST_SOURCE_TOKEN=from-file\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Token != "from-file" {
+			t.Errorf("expected %q, got %q", "from-file", cfg.Token)
+		}
+	})
+
+	t.Run("changed after loading", func(t *testing.T) {
+		t.Setenv("TEST_SOURCE_TOKEN", "")
+		if err := dotenv.Overload(writeEnvFile(t, ".env", "TEST_SOURCE_TOKEN=from-file\n")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		os.Setenv("TEST_SOURCE_TOKEN", "inherited")
+
+		var cfg ConfigSource
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Token != "" {
+			t.Errorf("expected a variable changed since loading to be ignored, got %q", cfg.Token)
+		}
+	})
+
+	t.Run("env only", func(t *testing.T) {
+		dotenv.ClearStore()
+		t.Cleanup(dotenv.ClearStore)
+		t.Setenv("TEST_SOURCE_REGION", "")
+		os.Unsetenv("TEST_SOURCE_REGION")
+		dotenv.Set("TEST_SOURCE_REGION", "stored")
+
+		var cfg ConfigSource
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Region != "local" {
+			t.Errorf("expected the stored value to be ignored, got %q", cfg.Region)
+		}
+
+		t.Setenv("TEST_SOURCE_REGION", "eu-west-1")
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Region != "eu-west-1" {
+			t.Errorf("expected %q, got %q", "eu-west-1", cfg.Region)
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		t.Setenv("TEST_SOURCE_TOKEN", "inherited")
+		t.Setenv("TEST_SOURCE_REGION", "")
+		os.Unsetenv("TEST_SOURCE_REGION")
+		t.Setenv("TEST_SOURCE_NAME", "inherited")
+
+		path := writeEnvFile(t, ".env", "TEST_SOURCE_TOKEN=from-file\nTEST_SOURCE_REGION=from-file\nTEST_SOURCE_NAME=from-file\n")
+
+		var cfg ConfigSource
+		if err := dotenv.Decode(&cfg, path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := ConfigSource{Token: "from-file", Region: "local", Name: "inherited"}
+		if cfg != expected {
+			t.Errorf("expected %+v, got %+v", expected, cfg)
+		}
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		var cfg struct {
+			Value string `env:"TEST_SOURCE_NAME" source:"vault"`
+		}
+
+		err := dotenv.Unmarshal(&cfg)
+		if err == nil || !strings.Contains(err.Error(), `unknown source "vault"`) {
+			t.Fatalf("expected unknown source error, got %v", err)
+		}
+	})
+}
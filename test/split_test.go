@@ -0,0 +1,58 @@
+package dotenv_test
+
+import (
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+type HostPort struct {
+	Host string
+	Port int
+}
+
+type SplitConfig struct {
+	Addr HostPort `env:"TEST_ADDR" split:":"`
+}
+
+func TestUnmarshalSplit(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		t.Setenv("TEST_ADDR", "localhost:8080")
+
+		var cfg SplitConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Addr.Host != "localhost" || cfg.Addr.Port != 8080 {
+			t.Errorf("unexpected address: %+v", cfg.Addr)
+		}
+
+		data, err := dotenv.Marshal(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(data); got != "TEST_ADDR=localhost:8080\n" {
+			t.Errorf("Marshal: unexpected output %q", got)
+		}
+	})
+
+	t.Run("missing part returns error", func(t *testing.T) {
+		t.Setenv("TEST_ADDR", "localhost")
+
+		var cfg SplitConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected split error, got nil")
+		}
+	})
+
+	t.Run("invalid part returns error", func(t *testing.T) {
+		t.Setenv("TEST_ADDR", "localhost:http")
+
+		var cfg SplitConfig
+		if err := dotenv.Unmarshal(&cfg); err == nil {
+			t.Fatal("expected port parse error, got nil")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package dotenv_test
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestStore(t *testing.T) {
+	t.Cleanup(dotenv.ClearStore)
+
+	t.Run("load does not touch process env", func(t *testing.T) {
+		dotenv.ClearStore()
+		os.Unsetenv("TEST_STORE_HOST")
+
+		path := writeEnvFile(t, ".env", "TEST_STORE_HOST=store.local\n")
+		if err := dotenv.LoadStore(path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := dotenv.Get("TEST_STORE_HOST"); got != "store.local" {
+			t.Errorf("Get: expected %q, got %q", "store.local", got)
+		}
+
+		if _, ok := os.LookupEnv("TEST_STORE_HOST"); ok {
+			t.Error("LoadStore should not set the process environment")
+		}
+	})
+
+	t.Run("store takes precedence over process env", func(t *testing.T) {
+		dotenv.ClearStore()
+		t.Setenv("TEST_STORE_NAME", "from-env")
+
+		if got := dotenv.Get("TEST_STORE_NAME"); got != "from-env" {
+			t.Errorf("Get: expected fallback %q, got %q", "from-env", got)
+		}
+
+		dotenv.Set("TEST_STORE_NAME", "from-store")
+
+		if got := dotenv.Get("TEST_STORE_NAME"); got != "from-store" {
+			t.Errorf("Get: expected %q, got %q", "from-store", got)
+		}
+
+		if got := os.Getenv("TEST_STORE_NAME"); got != "from-env" {
+			t.Errorf("os.Getenv: expected %q, got %q", "from-env", got)
+		}
+	})
+
+	t.Run("clear removes values", func(t *testing.T) {
+		dotenv.Set("TEST_STORE_CLEARED", "value")
+		dotenv.ClearStore()
+
+		if _, ok := dotenv.Lookup("TEST_STORE_CLEARED"); ok {
+			t.Error("expected value to be cleared")
+		}
+	})
+
+	t.Run("unmarshal reads the store", func(t *testing.T) {
+		dotenv.ClearStore()
+		dotenv.Set("TEST_OPTIONAL_NAME", "stored")
+		dotenv.Set("TEST_OPTIONAL_PORT", "4000")
+
+		var cfg OptionalConfig
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cfg.Name != "stored" || cfg.Port != 4000 {
+			t.Errorf("unexpected config: %+v", cfg)
+		}
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		dotenv.ClearStore()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dotenv.Set("TEST_STORE_CONCURRENT", "value")
+				dotenv.Get("TEST_STORE_CONCURRENT")
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestCollectPrefix(t *testing.T) {
+	dotenv.ClearStore()
+	t.Cleanup(dotenv.ClearStore)
+
+	t.Setenv("TEST_PLUGIN_CACHE_SIZE", "64")
+	t.Setenv("TEST_PLUGIN_CACHE_TTL", "30s")
+	t.Setenv("TEST_PLUGIN_CACHE_", "skipped")
+	t.Setenv("TEST_PLUGIN_OTHER", "ignored")
+	dotenv.Set("TEST_PLUGIN_CACHE_TTL", "1m")
+
+	got := dotenv.CollectPrefix("TEST_PLUGIN_CACHE_")
+	expected := map[string]string{"SIZE": "64", "TTL": "1m"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if got := dotenv.CollectPrefix("TEST_PLUGIN_MISSING_"); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty map, got %#v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package dotenv_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestUnmarshalStrictTypes(t *testing.T) {
+	type config struct {
+		Host   string   `env:"STRICT_HOST"`
+		Events chan int `env:"STRICT_EVENTS"`
+	}
+
+	t.Run("unsupported field fails before reading variables", func(t *testing.T) {
+		t.Setenv("STRICT_HOST", "localhost")
+		os.Unsetenv("STRICT_EVENTS")
+
+		var cfg config
+		err := dotenv.UnmarshalOptions{StrictTypes: true}.Unmarshal(&cfg)
+		if err == nil {
+			t.Fatal("expected error for chan field, got nil")
+		}
+
+		if !strings.Contains(err.Error(), "Events (chan int)") {
+			t.Errorf("expected error naming Events, got %v", err)
+		}
+
+		if cfg.Host != "" {
+			t.Errorf("expected no field to be set, got Host %q", cfg.Host)
+		}
+	})
+
+	t.Run("without strict types unset field is ignored", func(t *testing.T) {
+		t.Setenv("STRICT_HOST", "localhost")
+		os.Unsetenv("STRICT_EVENTS")
+
+		var cfg config
+		if err := dotenv.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("supported types pass", func(t *testing.T) {
+		t.Setenv("TEST_HOST", "localhost")
+		t.Setenv("TEST_PORT", "8080")
+		t.Setenv("TEST_DEBUG", "true")
+		t.Setenv("TEST_RATE", "0.5")
+
+		var cfg ConfigTest
+		opts := dotenv.UnmarshalOptions{StrictTypes: true}
+		if err := opts.Unmarshal(&cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package dotenv_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestUpdateFile(t *testing.T) {
+	content := `# Database settings
+DB_HOST=localhost # primary
+export DB_PORT=5432
+
+# Feature flags
+FEATURE_X=false
+`
+	path := writeEnvFile(t, ".env", content)
+
+	updates := map[string]string{
+		"DB_PORT":  "6543",
+		"NEW_NAME": "hello world",
+	}
+
+	if err := dotenv.UpdateFile(path, updates); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# Database settings
+DB_HOST=localhost # primary
+export DB_PORT=6543
+
+# Feature flags
+FEATURE_X=false
+NEW_NAME="hello world"
+`
+	if got := string(data); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestUpdateFileMissing(t *testing.T) {
+	if err := dotenv.UpdateFile("does-not-exist.env", map[string]string{"KEY": "value"}); err == nil {
+		t.Fatal("expected error for missing file, got nil")
+	}
+}
+
+func TestUpdateFileMultiline(t *testing.T) {
+	path := writeEnvFile(t, ".env", "BEFORE=1\nTEST_ML=\"line1\nline2\"\nAFTER=2\n")
+
+	if err := dotenv.UpdateFile(path, map[string]string{"TEST_ML": "new1\nnew2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "BEFORE=1\nTEST_ML=\"new1\\nnew2\"\nAFTER=2\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	loader := dotenv.Loader{Filenames: []string{path}}
+	vars, err := loader.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if vars["TEST_ML"] != "new1\nnew2" || vars["AFTER"] != "2" {
+		t.Errorf("expected the value to read back unchanged, got %q", vars)
+	}
+}
+
+func TestUpdateFileSkipsOtherMultilineValues(t *testing.T) {
+	content := "TEST_CERT=\"line1\nTEST_PORT=1\n\"\nTEST_PORT=2\n"
+	path := writeEnvFile(t, ".env", content)
+
+	if err := dotenv.UpdateFile(path, map[string]string{"TEST_PORT": "9"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "TEST_CERT=\"line1\nTEST_PORT=1\n\"\nTEST_PORT=9\n"
+	if got := string(data); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,55 @@
+package dotenv_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/rickferrdev/dotenv"
+)
+
+func TestCollectURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config.env" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte("TEST_URL_HOST=remote.local\nTEST_URL_PORT=\"9000\" # remote\n"))
+	}))
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		t.Setenv("TEST_URL_HOST", "")
+		t.Setenv("TEST_URL_PORT", "")
+
+		if err := dotenv.CollectURL(context.Background(), server.URL+"/config.env", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := os.Getenv("TEST_URL_HOST"); got != "remote.local" {
+			t.Errorf("TEST_URL_HOST: expected %q, got %q", "remote.local", got)
+		}
+
+		if got := os.Getenv("TEST_URL_PORT"); got != "9000" {
+			t.Errorf("TEST_URL_PORT: expected %q, got %q", "9000", got)
+		}
+	})
+
+	t.Run("non 200 returns error", func(t *testing.T) {
+		if err := dotenv.CollectURL(context.Background(), server.URL+"/missing.env", server.Client()); err == nil {
+			t.Fatal("expected error for 404, got nil")
+		}
+	})
+
+	t.Run("canceled context returns error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := dotenv.CollectURL(ctx, server.URL+"/config.env", nil); err == nil {
+			t.Fatal("expected error for canceled context, got nil")
+		}
+	})
+}
@@ -0,0 +1,323 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// UnmarshalOptions configures how a struct is filled from environment
+// variables. The zero value behaves like Unmarshal.
+type UnmarshalOptions struct {
+	// StrictTypes checks every tagged field before any variable is read and
+	// returns an error listing the fields whose types cannot be decoded.
+	// Without it, such a field only fails once its variable is set.
+	StrictTypes bool
+
+	// StripPrefix is removed from the start of every variable name before
+	// it is matched against the 'env' tags, for platforms that namespace all
+	// variables. With StripPrefix "MYAPP_", MYAPP_PORT fills `env:"PORT"`,
+	// taking precedence over PORT itself.
+	StripPrefix string
+
+	// RequireAll makes every tagged field required unless it is tagged
+	// `optional:"true"` or has a default. All missing fields are reported
+	// together in one error instead of stopping at the first.
+	RequireAll bool
+
+	// AllErrors keeps going after a field fails to convert or a required
+	// field is missing, and returns every problem joined with errors.Join.
+	// The fields that succeeded are still set. AfterUnmarshal is only
+	// called when there were no errors.
+	AllErrors bool
+
+	// TreatEmptyAsSet makes a variable that is present but empty, as in a
+	// KEY= line, set a string field to "" instead of counting as unset, so
+	// the field's default is skipped and its required check passes. Fields
+	// of other types still treat an empty value as unset.
+	TreatEmptyAsSet bool
+
+	// merge keeps the current value of fields whose variables are absent or
+	// empty, applying defaults only to zero fields. It is set by MergeEnv.
+	merge bool
+}
+
+// Unmarshal fills dest like the package-level Unmarshal, using the options.
+func (o UnmarshalOptions) Unmarshal(dest interface{}) error {
+	return o.unmarshal(dest, source{
+		lookup:  Lookup,
+		environ: environ,
+		file: func(key string) (string, bool) {
+			if value, ok := storeLookup(key); ok {
+				return value, true
+			}
+			if isLoaded(key) {
+				return os.LookupEnv(key)
+			}
+			return "", false
+		},
+		env: func(key string) (string, bool) {
+			if isLoaded(key) {
+				return "", false
+			}
+			return os.LookupEnv(key)
+		},
+	})
+}
+
+// source is where unmarshal reads variables from.
+type source struct {
+	lookup  func(key string) (string, bool)
+	environ func() []string
+
+	// file and env, when non-nil, look a key up only among the variables
+	// read from files, or only among those inherited by the process, for
+	// fields tagged with 'source'. Sources that cannot tell the two apart
+	// leave them nil.
+	file func(key string) (string, bool)
+	env  func(key string) (string, bool)
+}
+
+// restrict returns the lookup function for a field's 'source' tag: "file",
+// "env", or "" for both.
+func (s source) restrict(name string) func(key string) (string, bool) {
+	switch {
+	case name == "file" && s.file != nil:
+		return s.file
+	case name == "env" && s.env != nil:
+		return s.env
+	default:
+		return s.lookup
+	}
+}
+
+// mapSource returns a source that reads only from vars.
+func mapSource(vars map[string]string) source {
+	return source{
+		lookup: func(key string) (string, bool) {
+			value, ok := vars[key]
+			return value, ok
+		},
+		environ: func() []string {
+			env := make([]string, 0, len(vars))
+			for key, value := range vars {
+				env = append(env, key+"="+value)
+			}
+			return env
+		},
+	}
+}
+
+// stripPrefix returns a source in which every variable named prefix+NAME is
+// also visible as NAME, overriding a variable already named NAME.
+func (s source) stripPrefix(prefix string) source {
+	return source{
+		lookup: withPrefix(s.lookup, prefix),
+		file:   withPrefix(s.file, prefix),
+		env:    withPrefix(s.env, prefix),
+		environ: func() []string {
+			env := s.environ()
+			for _, entry := range env {
+				if strings.HasPrefix(entry, prefix) {
+					env = append(env, strings.TrimPrefix(entry, prefix))
+				}
+			}
+			return env
+		},
+	}
+}
+
+// withPrefix returns a lookup function that tries prefix+key before key. A
+// nil lookup stays nil.
+func withPrefix(lookup func(key string) (string, bool), prefix string) func(key string) (string, bool) {
+	if lookup == nil {
+		return nil
+	}
+
+	return func(key string) (string, bool) {
+		if value, ok := lookup(prefix + key); ok {
+			return value, true
+		}
+		return lookup(key)
+	}
+}
+
+// unmarshal fills dest from src according to the options.
+func (o UnmarshalOptions) unmarshal(dest interface{}, src source) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("dest must be a non-nil pointer")
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("dest must be a pointer to a struct")
+	}
+
+	if o.StripPrefix != "" {
+		src = src.stripPrefix(o.StripPrefix)
+	}
+
+	fields := cachedFields(rv.Type())
+	if o.StrictTypes {
+		if err := checkTypes(rv.Type(), fields); err != nil {
+			return err
+		}
+	}
+	resolved := make(map[string]string, len(fields))
+	var missing []string
+
+	// fail records err when AllErrors is set and returns nil so the loop
+	// moves on to the next field; otherwise it returns err to stop.
+	var errs []error
+	fail := func(err error) error {
+		if !o.AllErrors {
+			return err
+		}
+		errs = append(errs, err)
+		return nil
+	}
+
+	for _, f := range fields {
+		if f.prefix != "" {
+			if err := setMapField(rv.FieldByIndex(f.index), f.prefix, src.environ()); err != nil {
+				if err := fail(fmt.Errorf("error setting field %s: %w", f.name, err)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if f.source != "" && f.source != "file" && f.source != "env" {
+			if err := fail(fmt.Errorf("error setting field %s: unknown source %q", f.name, f.source)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A field's value is resolved in one order: the primary key, then
+		// each alias in tag order, taking the first non-empty value; then
+		// the 'defaultIf' rule when its condition holds; then the 'default'
+		// tag; and last the required check. Two cases cut in before the
+		// defaults: a pointer explicitly set to NullValue, or to empty
+		// outside merge, becomes nil, and with merge a field that already
+		// holds a value keeps it. With TreatEmptyAsSet, a string field whose
+		// variable is present but empty is set to "" right away.
+		value, exists := f.lookup(src)
+		if value == "" && o.merge && !rv.FieldByIndex(f.index).IsZero() {
+			if p, err := fieldPair(rv, f); err == nil {
+				resolved[f.key] = p.value
+			}
+			continue
+		}
+
+		if f.pointer && exists && (value == NullValue || (value == "" && !o.merge)) {
+			rv.FieldByIndex(f.index).SetZero()
+			continue
+		}
+
+		if value == "" && exists && o.TreatEmptyAsSet && rv.FieldByIndex(f.index).Kind() == reflect.String {
+			rv.FieldByIndex(f.index).SetString("")
+			resolved[f.key] = ""
+			continue
+		}
+
+		if value == "" && f.defaultIf != "" {
+			value = f.conditionalDefault(resolved, src)
+		}
+
+		if value == "" {
+			value = f.defaultValue
+		}
+
+		if value == "" {
+			switch {
+			case o.RequireAll && !f.optional:
+				missing = append(missing, f.name)
+				continue
+			case f.required:
+				if err := fail(fmt.Errorf("error %s tag needs to be filled in", f.name)); err != nil {
+					return err
+				}
+				continue
+			default:
+				continue
+			}
+		}
+
+		if f.expand {
+			value = expand(value, src.lookup)
+		}
+
+		value, err := f.choose(value)
+		if err != nil {
+			if err := fail(fmt.Errorf("error setting field %s: %w", f.name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case f.flag != "":
+			err = setFlagField(rv.FieldByIndex(f.index), value, f.flag)
+		case f.split != "":
+			err = setSplitField(rv.FieldByIndex(f.index), value, f.split)
+		default:
+			err = setFormatted(rv.FieldByIndex(f.index), value, f.format)
+		}
+		if err != nil {
+			if err := fail(fmt.Errorf("error setting field %s: %w", f.name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		resolved[f.key] = value
+	}
+
+	if len(missing) > 0 {
+		if err := fail(fmt.Errorf("error required fields need to be filled in: %s", strings.Join(missing, ", "))); err != nil {
+			return err
+		}
+	}
+
+	if err := checkConditions(fields, resolved, src); err != nil {
+		if err := fail(err); err != nil {
+			return err
+		}
+	}
+
+	if err := checkGroups(fields, resolved); err != nil {
+		if err := fail(err); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if hook, ok := dest.(AfterUnmarshaler); ok {
+		return hook.AfterUnmarshal()
+	}
+
+	return nil
+}
+
+// checkTypes returns an error listing the fields of t whose types cannot be
+// decoded.
+func checkTypes(t reflect.Type, fields []field) error {
+	var unsupported []string
+	for _, f := range fields {
+		if !f.supported(t.FieldByIndex(f.index).Type) {
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", f.name, t.FieldByIndex(f.index).Type))
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("unsupported field types: %s", strings.Join(unsupported, ", "))
+	}
+
+	return nil
+}
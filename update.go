@@ -0,0 +1,129 @@
+package dotenv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UpdateFile sets the variables in updates in the file at path, keeping
+// the rest of the file byte-for-byte identical, including comments, blank
+// lines and ordering. A key that already exists is rewritten in place,
+// including every repeated definition, keeping its "export " prefix. Keys
+// not present in the file are appended at the end in sorted order. The file
+// is replaced atomically by writing a temporary file and renaming it.
+func UpdateFile(path string, updates map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	written := make(map[string]bool, len(updates))
+	var out []string
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		body := line
+		prefix := ""
+		if strings.HasPrefix(body, "export ") {
+			prefix = "export "
+			body = strings.TrimSpace(strings.TrimPrefix(body, "export"))
+		}
+
+		if body == "" || body[0] == '#' {
+			out = append(out, line)
+			continue
+		}
+
+		key, _, found := cutKey(body)
+		if !found {
+			out = append(out, line)
+			continue
+		}
+
+		// Find the last physical line of the value, which continues with
+		// trailing backslashes or inside a double quote left open. A value
+		// that is not updated is copied through unchanged, so its inner
+		// lines are never read as assignments.
+		end := i
+		joined := body
+		for continuesLine(lines[end]) && end+1 < len(lines) {
+			end++
+			joined = joined[:len(joined)-1] + lines[end]
+		}
+		if _, raw, _ := cutKey(joined); unterminatedQuote(raw) {
+			if j := closingLine(lines, end+1, '#'); j >= 0 {
+				end = j
+			}
+		}
+
+		value, update := updates[key]
+		if !update {
+			out = append(out, lines[i:end+1]...)
+			i = end
+			continue
+		}
+
+		i = end
+		out = append(out, prefix+formatAssignment(key, value))
+		written[key] = true
+	}
+
+	var added []string
+	for key := range updates {
+		if !written[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+
+	if len(added) > 0 {
+		// Append before the final empty element left by a trailing newline.
+		trailing := len(out) > 0 && out[len(out)-1] == ""
+		if trailing {
+			out = out[:len(out)-1]
+		}
+
+		for _, key := range added {
+			out = append(out, formatAssignment(key, updates[key]))
+		}
+		out = append(out, "")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strings.Join(out, "\n")); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// formatAssignment returns a single KEY=VALUE line, quoted like Marshal.
+func formatAssignment(key, value string) string {
+	var buf bytes.Buffer
+	(MarshalOptions{}).writePairs(&buf, []pair{{key: key, value: value}})
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,46 @@
+package dotenv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CollectURL fetches a .env file served over HTTP(S), parses it like a file
+// and sets the resulting variables in the process environment. A nil client
+// uses http.DefaultClient. Any status other than 200 OK is an error, and the
+// request is canceled when ctx is done.
+func CollectURL(ctx context.Context, url string, client *http.Client) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	vars := make(map[string]string)
+	loader := Loader{}
+	if _, err := loader.parse(url, string(content), vars); err != nil {
+		return err
+	}
+
+	return loader.applyVars(vars, nil, true)
+}
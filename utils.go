@@ -2,58 +2,471 @@
 package dotenv
 
 import (
+	"encoding"
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // It performs the following cleanup steps:
-//  1. If the value starts with a single (') or double (") quote, it extracts
-//     everything until the matching closing quote.
-//  2. If no matching quote is found, it strips the leading quote.
-//  3. It removes any trailing comments starting with "#" (only for unquoted
-//     content or after the closing quote).
-//  4. It trims leading and trailing whitespace from the final result.
-func quotes(value string) string {
+//  1. It drops spaces and tabs before the value, so `KEY=  "quoted"` is
+//     treated as quoted.
+//  2. If the value starts with a single (') or double (") quote that is
+//     matched by a closing quote of the same kind at the end of the value,
+//     or before an inline comment, it extracts everything between them,
+//     keeping leading and trailing spaces. Single-quoted content is
+//     returned exactly as written. Double-quoted content has its escapes
+//     resolved by unescapeQuoted; a backslash before a double quote escapes
+//     it, so "a\\" ends with a backslash while "a\"" ends with a quote.
+//  3. Otherwise, as with "mixed' or "value"extra, the value is read as
+//     unquoted text, keeping the leading quote. Loader.Strict rejects both
+//     forms.
+//  4. It removes any trailing comment (only for unquoted content or after
+//     the closing quote). A comment starts at a comment character preceded
+//     by a space or tab, so values such as abc#def or #ff0000 are kept.
+//  5. It trims leading and trailing whitespace from unquoted results.
+func quotes(value string, comment byte) string {
 	if len(value) == 0 {
 		return ""
 	}
 
-	quote := value[0]
-	if quote == '"' || quote == '\'' {
-		content, _, found := strings.Cut(value[1:], string(quote))
-		if found {
-			return content
+	// Fast path: most values are bare words that need no processing, so
+	// return the input unchanged without scanning it more than once.
+	first, last := value[0], value[len(value)-1]
+	if first != '"' && first != '\'' && !isSpace(first) && !isSpace(last) &&
+		first < utf8.RuneSelf && last < utf8.RuneSelf &&
+		strings.IndexByte(value, comment) < 0 {
+		return value
+	}
+
+	trimmed := strings.TrimLeft(value, " \t")
+	spaced := len(trimmed) < len(value)
+	value = trimmed
+	if len(value) == 0 {
+		return ""
+	}
+
+	if end := matchedQuote(value, comment); end >= 0 {
+		if value[0] == '"' {
+			return unescapeQuoted(value[1:end])
 		}
+		return value[1:end]
+	}
 
-		value = value[1:]
+	if i := commentIndex(value, comment, spaced); i >= 0 {
+		value = value[:i]
 	}
-	value, _, _ = strings.Cut(value, "#")
 	return strings.TrimSpace(value)
 }
 
+// matchedQuote returns the index of the quote that closes value, which has
+// no leading whitespace, when value starts with a quote and is closed by
+// the same kind of quote followed only by whitespace and an optional inline
+// comment. Otherwise it returns -1 and value is read as unquoted text.
+func matchedQuote(value string, comment byte) int {
+	if len(value) == 0 || (value[0] != '"' && value[0] != '\'') {
+		return -1
+	}
+
+	end := closingQuote(value)
+	if end < 0 {
+		return -1
+	}
+
+	rest := strings.TrimSpace(value[end+1:])
+	if rest != "" && rest[0] != comment {
+		return -1
+	}
+	return end
+}
+
+// closingQuote returns the index of the quote that closes the quoted value,
+// which starts with its opening quote, or -1 when it is unterminated. Inside
+// double quotes a backslash escapes the next character, so \" and \\ never
+// close the value.
+func closingQuote(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[i] == quote:
+			return i
+		case value[i] == '\\' && quote == '"':
+			i++
+		}
+	}
+	return -1
+}
+
+// unescapeQuoted resolves the escapes of double-quoted content: \\, \",
+// \n, \t, \r, \xXX and the JSON-style \uXXXX, including surrogate pairs.
+// Any other backslash is kept, so \$ still reaches variable expansion as an
+// escaped dollar sign, and so is a malformed \x or \u escape.
+func unescapeQuoted(value string) string {
+	if strings.IndexByte(value, '\\') < 0 {
+		return value
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '\\', '"':
+			builder.WriteByte(value[i+1])
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'x', 'u':
+			r, n, ok := hexEscape(value[i:])
+			if !ok {
+				builder.WriteByte('\\')
+				builder.WriteByte(value[i+1])
+				break
+			}
+			builder.WriteRune(r)
+			i += n - 2
+		default:
+			builder.WriteByte('\\')
+			builder.WriteByte(value[i+1])
+		}
+		i++
+	}
+	return builder.String()
+}
+
+// hexEscape decodes the \xXX or \uXXXX escape at the start of s and returns
+// its rune and length. A \u escape holding a high surrogate must be followed
+// by a \u escape holding the low one. ok is false for a malformed escape.
+func hexEscape(s string) (r rune, n int, ok bool) {
+	size := 4
+	if s[1] == 'x' {
+		size = 2
+	}
+
+	n = 2 + size
+	if len(s) < n {
+		return 0, 0, false
+	}
+	v, err := strconv.ParseUint(s[2:n], 16, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	r = rune(v)
+	if s[1] == 'x' || !utf16.IsSurrogate(r) {
+		return r, n, true
+	}
+
+	if len(s) < n+6 || s[n:n+2] != `\u` {
+		return 0, 0, false
+	}
+	low, err := strconv.ParseUint(s[n+2:n+6], 16, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	if r = utf16.DecodeRune(r, rune(low)); r == utf8.RuneError {
+		return 0, 0, false
+	}
+	return r, n + 6, true
+}
+
+// invalidEscape returns an error for the first malformed \x or \u escape in
+// a double-quoted raw value, or nil when there is none.
+func invalidEscape(raw string) error {
+	raw = strings.TrimLeft(raw, " \t")
+	if len(raw) == 0 || raw[0] != '"' {
+		return nil
+	}
+
+	end := closingQuote(raw)
+	if end < 0 {
+		return nil
+	}
+
+	content := raw[1:end]
+	for i := 0; i+1 < len(content); i++ {
+		if content[i] != '\\' {
+			continue
+		}
+
+		if c := content[i+1]; c == 'x' || c == 'u' {
+			_, n, ok := hexEscape(content[i:])
+			if !ok {
+				return fmt.Errorf("invalid escape sequence in %q", content[i:min(i+6, len(content))])
+			}
+			i += n - 2
+		}
+		i++
+	}
+	return nil
+}
+
+// unterminatedQuote reports whether raw opens a double-quoted value that is
+// never closed.
+func unterminatedQuote(raw string) bool {
+	raw = strings.TrimLeft(raw, " \t")
+	return len(raw) > 0 && raw[0] == '"' && closingQuote(raw) < 0
+}
+
+// closingLine returns the index of the line, from lines[start] on, that
+// closes a double-quoted value left open on an earlier line, or -1 when no
+// line does or the line goes on past the closing quote with anything but
+// whitespace and an inline comment. Each line is scanned once, so a quote
+// that is never closed costs linear time. A backslash at the end of a line
+// escapes the line break, so every line starts unescaped.
+func closingLine(lines []string, start int, comment byte) int {
+	for j := start; j < len(lines); j++ {
+		line := lines[j]
+		for k := 0; k < len(line); k++ {
+			switch line[k] {
+			case '\\':
+				k++
+			case '"':
+				rest := strings.TrimSpace(line[k+1:])
+				if rest != "" && rest[0] != comment {
+					return -1
+				}
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// trailingText reports whether raw is a closed quoted value followed by
+// something other than whitespace and an inline comment.
+func trailingText(raw string, comment byte) bool {
+	raw = strings.TrimLeft(raw, " \t")
+	if len(raw) == 0 || (raw[0] != '"' && raw[0] != '\'') {
+		return false
+	}
+
+	end := closingQuote(raw)
+	if end < 0 {
+		return false
+	}
+
+	rest := strings.TrimSpace(raw[end+1:])
+	return rest != "" && rest[0] != comment
+}
+
+// misquoted returns an error describing why raw looks misquoted, or nil.
+// It flags a quoted value that is never closed, a single-quoted value
+// holding an odd number of double quotes, and an unquoted value that ends
+// in a quote or holds an odd number of double quotes.
+func misquoted(raw string, comment byte) error {
+	trimmed := strings.TrimLeft(raw, " \t")
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	switch quote := trimmed[0]; quote {
+	case '"', '\'':
+		end := closingQuote(trimmed)
+		if end < 0 {
+			return fmt.Errorf("unbalanced %c quote", quote)
+		}
+
+		// Apostrophes are common inside double quotes, as in "it's", so
+		// only a stray double quote inside single quotes is flagged.
+		if quote == '\'' && strings.Count(trimmed[1:end], `"`)%2 == 1 {
+			return errors.New(`mismatched quotes: " inside a single-quoted value`)
+		}
+		return nil
+	}
+
+	value := trimmed
+	if i := commentIndex(value, comment, len(trimmed) < len(raw)); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	if last := value[len(value)-1]; last == '"' || last == '\'' {
+		return fmt.Errorf("trailing %c quote on an unquoted value", last)
+	}
+	if strings.Count(value, `"`)%2 == 1 {
+		return errors.New(`unbalanced " quote`)
+	}
+	return nil
+}
+
+// commentIndex returns the index in value of the comment character that
+// starts an inline comment, or -1 when there is none. The character only
+// starts a comment when it follows a space or tab; spaced reports whether
+// whitespace came right before value, which makes a leading one count too.
+func commentIndex(value string, comment byte, spaced bool) int {
+	for i := 0; i < len(value); i++ {
+		if value[i] != comment {
+			continue
+		}
+
+		if (i == 0 && spaced) || (i > 0 && (value[i-1] == ' ' || value[i-1] == '\t')) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isSpace reports whether c is an ASCII whitespace character, matching the
+// characters strings.TrimSpace removes from ASCII input.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// quoteOf returns the quote that encloses a raw value, following the rules
+// of quotes, or 0 when the value is read as unquoted text.
+func quoteOf(value string, comment byte) byte {
+	value = strings.TrimLeft(value, " \t")
+	if matchedQuote(value, comment) < 0 {
+		return 0
+	}
+	return value[0]
+}
+
+// isQuoted reports whether a raw value is enclosed in matching quotes.
+func isQuoted(value string, comment byte) bool {
+	return quoteOf(value, comment) != 0
+}
+
+// continuesLine reports whether line ends in a line continuation: an odd
+// number of trailing backslashes. An even number, such as a trailing "\\",
+// is an escaped backslash and ends the line.
+func continuesLine(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// cutKey splits a line into its key and raw value at the assignment sign.
+// A key that starts with a quote extends to the matching closing quote and
+// may contain "=", so `"a=b"=value` yields the key a=b. The "=" must follow
+// the closing quote directly.
+func cutKey(line string) (key, value string, found bool) {
+	if len(line) > 0 && (line[0] == '"' || line[0] == '\'') {
+		key, rest, closed := strings.Cut(line[1:], string(line[0]))
+		if !closed || !strings.HasPrefix(rest, "=") {
+			return "", "", false
+		}
+		return key, rest[1:], true
+	}
+
+	return strings.Cut(line, "=")
+}
+
+// inlineComment returns the comment that follows a raw value, using the
+// same quote rules as quotes. The comment character and surrounding
+// whitespace are removed. It returns "" when the value has no inline comment.
+func inlineComment(value string, comment byte) string {
+	trimmed := strings.TrimLeft(value, " \t")
+	spaced := len(trimmed) < len(value)
+	value = trimmed
+	if len(value) == 0 {
+		return ""
+	}
+
+	if end := matchedQuote(value, comment); end >= 0 {
+		value = strings.TrimLeft(value[end+1:], " \t")
+		spaced = true
+	}
+
+	i := commentIndex(value, comment, spaced)
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(value[i+1:])
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
 // setField helps convert string values to basic Go types supported by the struct fields.
+// Types implementing encoding.TextUnmarshaler decode the value themselves, and
+// time.Duration fields are parsed with time.ParseDuration, as in "30s".
 func setField(field reflect.Value, value string) error {
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as %s: %w", value, field.Type(), err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
 	switch field.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
 	case reflect.String:
 		field.SetString(value)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type: %s", field.Type())
+		}
+		field.SetBytes([]byte(value))
 	case reflect.Bool:
 		b, err := strconv.ParseBool(value)
 		if err != nil {
-			return err
+			return parseError(field, value, err)
 		}
 		field.SetBool(b)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.ParseInt(value, 10, 64)
+		digits, err := normalizeInt(value)
 		if err != nil {
-			return err
+			return parseError(field, value, err)
+		}
+		i, err := strconv.ParseInt(digits, 10, field.Type().Bits())
+		if err != nil {
+			return parseError(field, value, err)
 		}
 		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		digits, err := normalizeInt(value)
+		if err != nil {
+			return parseError(field, value, err)
+		}
+		if strings.HasPrefix(digits, "-") {
+			return parseError(field, value, errors.New("negative value for unsigned type"))
+		}
+		u, err := strconv.ParseUint(digits, 10, field.Type().Bits())
+		if err != nil {
+			return parseError(field, value, err)
+		}
+		field.SetUint(u)
 	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(value, 64)
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
 		if err != nil {
-			return err
+			return parseError(field, value, err)
+		}
+		// ParseFloat accepts "Inf" and "NaN", which are almost never
+		// intended as configuration values.
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return parseError(field, value, errors.New("infinity and NaN are not allowed"))
 		}
 		field.SetFloat(f)
 	default:
@@ -61,3 +474,84 @@ func setField(field reflect.Value, value string) error {
 	}
 	return nil
 }
+
+// normalizeInt prepares a decimal integer for strconv, which is shared by
+// signed and unsigned fields. It drops a leading plus sign and the
+// underscores used to group digits, so "+100", "1_000" and "100" are read
+// alike. An underscore must sit between two digits; anything else is
+// strconv.ErrSyntax. A leading minus sign is kept for the caller to judge.
+func normalizeInt(value string) (string, error) {
+	sign, digits := "", value
+	if len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		if digits[0] == '-' {
+			sign = "-"
+		}
+		digits = digits[1:]
+	}
+
+	if len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		return "", strconv.ErrSyntax
+	}
+
+	if strings.IndexByte(digits, '_') < 0 {
+		return sign + digits, nil
+	}
+
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(digits)-1 || !isDigit(digits[i-1]) || !isDigit(digits[i+1]) {
+			return "", strconv.ErrSyntax
+		}
+	}
+	return sign + strings.ReplaceAll(digits, "_", ""), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseError describes a value that could not be converted to the type of
+// field, such as `cannot parse "30s" as int: invalid syntax`.
+func parseError(field reflect.Value, value string, err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+
+	return fmt.Errorf("cannot parse %q as %s: %w", value, field.Type(), err)
+}
+
+// setMapField fills a map field from the environ entries whose key starts
+// with prefix. The key without the prefix and the value are converted to the
+// map's key and element types with setField. The field is left unchanged
+// when no entry matches.
+func setMapField(field reflect.Value, prefix string, environ []string) error {
+	t := field.Type()
+	m := reflect.MakeMap(t)
+
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+
+		key := reflect.New(t.Key()).Elem()
+		if err := setField(key, strings.TrimPrefix(name, prefix)); err != nil {
+			return fmt.Errorf("invalid key %s: %w", name, err)
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err := setField(elem, value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+
+		m.SetMapIndex(key, elem)
+	}
+
+	if m.Len() > 0 {
+		field.Set(m)
+	}
+	return nil
+}
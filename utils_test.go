@@ -0,0 +1,93 @@
+package dotenv
+
+import "testing"
+
+var quotesCases = []struct {
+	name  string
+	value string
+}{
+	{name: "unquoted", value: "localhost"},
+	{name: "unquoted padded", value: "  localhost  "},
+	{name: "double quoted", value: `"quoted value"`},
+	{name: "single quoted", value: `'quoted value'`},
+	{name: "with comment", value: "8080 # external port"},
+	{name: "quoted with comment", value: `"secret" # inline comment`},
+}
+
+var quotesResult string
+
+func BenchmarkQuotes(b *testing.B) {
+	for _, tc := range quotesCases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				quotesResult = quotes(tc.value, '#')
+			}
+		})
+	}
+}
+
+func TestQuotes(t *testing.T) {
+	tests := map[string]string{
+		"localhost":                 "localhost",
+		"  localhost  ":             "localhost",
+		`"quoted value"`:            "quoted value",
+		`'quoted value'`:            "quoted value",
+		"8080 # external port":      "8080",
+		`"secret" # inline comment`: "secret",
+		`  "quoted"`:                "quoted",
+		`"unterminated`:             `"unterminated`,
+		`"mixed'`:                   `"mixed'`,
+		`'mixed" # note`:            `'mixed"`,
+		`"value"extra`:              `"value"extra`,
+		`"value"# note`:             "value",
+		"value\u00a0":               "value",
+		"\u00a0value":               "value",
+		"#":                         "#",
+		" # comment":                "",
+		"abc#def":                   "abc#def",
+		"abc #def":                  "abc",
+		"":                          "",
+		`"#"`:                       "#",
+		`"  "`:                      "  ",
+		`"a\\"`:                     `a\`,
+		`"a\""`:                     `a"`,
+		`"line\nbreak"`:             "line\nbreak",
+		`'a\n'`:                     `a\n`,
+		`"\$HOME"`:                  `\$HOME`,
+		`"caf\u00e9"`:               "café",
+		`"\x41\x42"`:                "AB",
+		`"\ud83d\ude00"`:            "\U0001F600",
+		`'\u00e9'`:                  `\u00e9`,
+		`"\u00g9"`:                  `\u00g9`,
+		`"\x4"`:                     `\x4`,
+		`"\ud83d"`:                  `\ud83d`,
+		`"\\u00e9"`:                 `\u00e9`,
+	}
+
+	for value, expected := range tests {
+		if got := quotes(value, '#'); got != expected {
+			t.Errorf("quotes(%q): expected %q, got %q", value, expected, got)
+		}
+	}
+}
+
+func TestTrimNewline(t *testing.T) {
+	tests := map[string]string{
+		"secret\n":       "secret",
+		"secret\r\n":     "secret",
+		"secret\n\n":     "secret\n",
+		"  secret  \n":   "  secret  ",
+		"line1\nline2\n": "line1\nline2",
+		"secret\r":       "secret\r",
+		"secret":         "secret",
+		"\n":             "",
+		"":               "",
+	}
+
+	for value, expected := range tests {
+		if got := trimNewline(value); got != expected {
+			t.Errorf("trimNewline(%q): expected %q, got %q", value, expected, got)
+		}
+	}
+}